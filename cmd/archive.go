@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <id>...",
+	Short: "Move notes to the archive, hiding them from list and timeline",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		for _, id := range args {
+			note, err := jot.FindNoteByID(cfg.Store(), id)
+			if err != nil {
+				printNoteError(os.Stderr, err)
+				failed = true
+				continue
+			}
+
+			warnBacklinks(note)
+
+			lock := lockNote(note.ID)
+			src, dest, err := jot.ArchiveNote(cfg.Store(), note.ID)
+			unlock(lock)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error archiving note:", err)
+				failed = true
+				continue
+			}
+			autoCommit("archive", note.ID, src, dest)
+			fmt.Printf("Archived note %s\n", note.ID)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <id>...",
+	Short: "Move archived notes back into the notes directory",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Unarchiving checks that no note in the vault has taken the note's ID meanwhile.
+		lock := lockVault()
+		defer unlock(lock)
+
+		failed := false
+		for _, id := range args {
+			src, dest, err := jot.UnarchiveNote(cfg.Store(), id)
+			if err != nil {
+				printNoteError(os.Stderr, err)
+				failed = true
+				continue
+			}
+			autoCommitMove("unarchive", src, dest)
+			fmt.Printf("Unarchived note %s\n", id)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
+}
@@ -2,19 +2,38 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/dalryan/jot/internal/jot"
 	"github.com/spf13/cobra"
 	"os"
 	"os/exec"
+	"strings"
 )
 
+var editOnConflict string
+
 var editCmd = &cobra.Command{
 	Use:   "edit [id]",
 	Short: "Edit a note by ID or from stdin",
+	Long: `Edit a note by ID or from stdin.
+
+The note is edited in a temporary copy. If the note file is changed by something else
+while the editor is open (another jot command or a sync tool), you are asked whether to
+merge both sets of changes, keep yours, keep theirs, or save yours as a conflict copy.
+Without a terminal to ask on, your changes are saved as a conflict copy unless
+--on-conflict says otherwise.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var id string
-		baseDir := cfg.StoragePath
+		store := cfg.Store()
+
+		switch editOnConflict {
+		case "", jot.ResolveMerge, jot.ResolveMine, jot.ResolveTheirs, jot.ResolveCopy:
+		default:
+			fmt.Printf("Error: invalid --on-conflict value '%s': use merge, mine, theirs or copy\n", editOnConflict)
+			os.Exit(1)
+		}
 
 		stat, err := os.Stdin.Stat()
 		if err != nil {
@@ -27,11 +46,9 @@ var editCmd = &cobra.Command{
 		} else if (stat.Mode() & os.ModeCharDevice) == 0 {
 			scanner := bufio.NewScanner(os.Stdin)
 			if scanner.Scan() {
-				line := scanner.Text()
-				if len(line) >= 8 {
-					id = line[:8]
-				} else {
-					fmt.Println("Error: Input too short")
+				id = jot.IDFromLine(scanner.Text())
+				if id == "" {
+					fmt.Println("Error: No ID found in input")
 					os.Exit(1)
 				}
 			} else {
@@ -43,25 +60,31 @@ var editCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		notePath, err := jot.ResolveNotePath(baseDir, id)
+		key, err := jot.ResolveNoteKey(store, id)
 		if err != nil {
-			fmt.Println("Error:", err)
+			printNoteError(os.Stdout, err)
 			os.Exit(1)
 		}
 
-		editor := cfg.Editor
-
-		c := exec.Command(editor, notePath)
+		data, err := store.Get(key)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		current, err := jot.ParseNote(data, key)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
 
 		// If stdin is a pipe, open /dev/tty to ensure the editor gets input from the terminal
 		// This prevents the "Input is not from a terminal" warning when piping to jot edit
 		// might be a vim specific issue. However, that's what I tested with.
-		if (stat.Mode() & os.ModeCharDevice) == 0 {
+		input, interactive := os.Stdin, (stat.Mode()&os.ModeCharDevice) != 0
+		if !interactive {
 			tty, err := os.Open("/dev/tty")
-			if err != nil {
-				c.Stdin = os.Stdin
-			} else {
-				c.Stdin = tty
+			if err == nil {
+				input, interactive = tty, true
 				defer func(tty *os.File) {
 					err := tty.Close()
 					if err != nil {
@@ -69,35 +92,189 @@ var editCmd = &cobra.Command{
 					}
 				}(tty)
 			}
-		} else {
-			c.Stdin = os.Stdin
 		}
 
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
+		editStoredNote("edit", key, current.ID, input, interactive)
+	},
+}
+
+// editStoredNote opens the note with the given ID, stored at key, in the editor and saves the
+// result, committing it as op. The lock is only held while reading and saving the note, not while
+// the editor is open, so other jot commands can still change it. Those changes are detected when
+// saving and resolved by resolveEditConflict, asking on input if interactive is set.
+func editStoredNote(op, key, id string, input *os.File, interactive bool) {
+	store := cfg.Store()
+	lock := lockNote(id)
+	if err := jot.SnapshotNote(store, key); err != nil {
+		fmt.Printf("Warning: could not record revision before editing: %v\n", err)
+	}
+	original, err := store.Get(key)
+	unlock(lock)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	tmp, err := os.CreateTemp("", "jot-"+id+"-*.md")
+	if err != nil {
+		fmt.Println("Error creating temporary file:", err)
+		os.Exit(1)
+	}
+	tmpPath := tmp.Name()
+	// Failures to save exit through keepEdit, which skips this and leaves the edit in place.
+	defer os.Remove(tmpPath)
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Println("Error writing temporary file:", err)
+		os.Exit(1)
+	}
+
+	if err := runEditor(tmpPath, input); err != nil {
+		fmt.Printf("Error opening editor: %v\n", err)
+		return
+	}
+
+	mine, err := os.ReadFile(tmpPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading edited note: %v; your edit may still be in %s\n", err, tmpPath)
+		os.Exit(1)
+	}
 
-		if err := c.Run(); err != nil {
-			fmt.Printf("Error opening editor: %v\n", err)
+	if bytes.Equal(mine, original) {
+		return
+	}
+
+	lock = lockNote(id)
+	defer unlock(lock)
+
+	theirs, err := store.Get(key)
+	switch {
+	case errors.Is(err, jot.ErrNotFound):
+		fmt.Printf("Warning: note %s was removed while editing; saving your version\n", id)
+	case err != nil:
+		keepEdit(tmpPath, mine, "Error re-reading note", err)
+	case !bytes.Equal(theirs, original):
+		mine = resolveEditConflict(id, original, mine, theirs, tmpPath, input, interactive)
+		if mine == nil {
 			return
 		}
+	}
 
-		note, err := jot.ParseNoteFile(notePath)
-		if err != nil {
-			fmt.Printf("Warning: could not parse edited note: %v\n", err)
-			return
+	note, err := jot.ParseNote(mine, tmpPath)
+	if err != nil {
+		keepEdit(tmpPath, mine, "Error parsing edited note", err)
+	}
+
+	note.UpdateTimestamp()
+	if err := jot.SaveNote(cfg, note); err != nil {
+		keepEdit(tmpPath, mine, "Error saving note", err)
+	}
+
+	autoCommit(op, note.ID, key)
+	fmt.Printf("Updated note %s\n", note.ID)
+}
+
+// runEditor opens path in the configured editor, reading input from in.
+func runEditor(path string, in *os.File) error {
+	c := exec.Command(cfg.Editor, path)
+	c.Stdin = in
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// resolveEditConflict settles a note that was changed by someone else while it was being edited.
+// original is the note when editing started, mine the edited version and theirs the note as it is now.
+// It returns the markdown to save, or nil if the note on disk should be left as it is.
+func resolveEditConflict(id string, original, mine, theirs []byte, tmpPath string, input *os.File, interactive bool) []byte {
+	fmt.Printf("Note %s was changed by someone else while editing.\n", id)
+
+	resolution := editOnConflict
+	if resolution == "" {
+		resolution = jot.ResolveCopy
+		if interactive {
+			resolution = promptResolution(input)
 		}
+	}
 
-		note.UpdateTimestamp()
-		if err := jot.SaveNote(cfg, note); err != nil {
-			fmt.Printf("Warning: could not update timestamp: %v\n", err)
+	switch resolution {
+	case jot.ResolveMine:
+		return mine
+	case jot.ResolveTheirs:
+		fmt.Printf("Kept their changes to note %s; your edit was discarded\n", id)
+		return nil
+	case jot.ResolveMerge:
+		merged, conflict := jot.MergeMarkdown(original, mine, theirs, "mine", "theirs")
+		if !conflict {
+			fmt.Println("Merged both sets of changes")
+			return merged
 		}
+		if interactive {
+			fmt.Println("The changes overlap; opening the merge result with conflict markers")
+			if err := os.WriteFile(tmpPath, merged, 0644); err == nil {
+				if err := runEditor(tmpPath, input); err == nil {
+					if resolved, err := os.ReadFile(tmpPath); err == nil {
+						return resolved
+					}
+				}
+			}
+		}
+		fmt.Println("The changes overlap and could not be merged; saving your version as a conflict copy")
+	}
 
-		fmt.Printf("Updated note %s\n", note.ID)
-	},
+	copyNote, err := jot.ConflictCopy(mine, id, jot.ConflictID(id))
+	if err != nil {
+		keepEdit(tmpPath, mine, "Error parsing edited note", err)
+	}
+	copyNote.UpdateTimestamp()
+	if err := jot.SaveNote(cfg, copyNote); err != nil {
+		keepEdit(tmpPath, mine, "Error saving conflict copy", err)
+	}
+	autoCommit("edit", copyNote.ID)
+	fmt.Printf("Kept their changes to note %s; your version was saved as note %s\n", id, copyNote.ID)
+	return nil
+}
+
+// keepEdit reports an error that stopped an edited note from being saved and exits, leaving the
+// edit in the temporary file so that it is not lost. The file is rewritten with data, the version
+// that was being saved, in case a merge replaced its contents.
+func keepEdit(tmpPath string, data []byte, context string, err error) {
+	if writeErr := os.WriteFile(tmpPath, data, 0644); writeErr != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v; your edit could not be kept: %v\n", context, err, writeErr)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v; your edit is still in %s\n", context, err, tmpPath)
+	os.Exit(1)
+}
+
+// promptResolution asks on the terminal how to resolve an edit conflict.
+func promptResolution(input *os.File) string {
+	reader := bufio.NewReader(input)
+	for {
+		fmt.Print("[m]erge, keep m[i]ne, keep [t]heirs or save a conflict [c]opy? ")
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return jot.ResolveCopy
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "m", "merge":
+			return jot.ResolveMerge
+		case "i", "mine":
+			return jot.ResolveMine
+		case "t", "theirs":
+			return jot.ResolveTheirs
+		case "c", "copy":
+			return jot.ResolveCopy
+		}
+	}
 }
 
 // init registers the edit command with the root command.
 // This function is automatically called by Go when the package is initialized.
 func init() {
+	editCmd.Flags().StringVar(&editOnConflict, "on-conflict", "", "How to resolve changes made by others while editing: merge, mine, theirs or copy")
 	rootCmd.AddCommand(editCmd)
 }
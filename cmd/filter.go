@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/dalryan/jot/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags registers the flags shared by the commands that filter notes.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("tag", nil, "Filter by tag(s); a tag such as proj also matches proj/atlas")
+	cmd.Flags().String("context", "", "Filter by context")
+	cmd.Flags().StringSlice("field", nil, "Filter by frontmatter field(s) (e.g. status=open)")
+	cmd.Flags().String("since", "", "Only notes created after (e.g. '7d' or '2025-04-01')")
+	cmd.Flags().String("before", "", "Only notes created before (e.g. '7d' or '2025-04-01')")
+	cmd.Flags().String("query", "", `Filter notes with a query, e.g. 'tag:go -tag:draft (context:work OR context:oss) updated:<7d'`)
+}
+
+// filterFlags builds a filter from the flags registered by addFilterFlags,
+// exiting with an error if a time or query cannot be parsed.
+func filterFlags(cmd *cobra.Command) jot.Filter {
+	var f jot.Filter
+	f.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	f.Context, _ = cmd.Flags().GetString("context")
+	f.Fields, _ = cmd.Flags().GetStringSlice("field")
+	f.Since = timeFlag(cmd, "since")
+	f.Before = timeFlag(cmd, "before")
+	f.Match = queryFlag(cmd).Match
+	return f
+}
+
+// timeFlag parses a time flag, returning the zero time if it is not set.
+func timeFlag(cmd *cobra.Command, name string) time.Time {
+	s, _ := cmd.Flags().GetString(name)
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := jot.ParseTime(s, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --%s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return t
+}
+
+// queryFlag parses the --query flag of cmd, exiting with the parse error pointed out if it is invalid.
+// With no query given, the returned expression matches every note.
+func queryFlag(cmd *cobra.Command) query.Expr {
+	q, _ := cmd.Flags().GetString("query")
+	expr, err := query.Parse(q)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		var perr *query.ParseError
+		if errors.As(err, &perr) {
+			fmt.Fprintln(os.Stderr, "  "+strings.ReplaceAll(perr.Pointer(), "\n", "\n  "))
+		}
+		os.Exit(1)
+	}
+	return expr
+}
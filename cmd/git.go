@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Inspect the git history of the notes repository",
+}
+
+var gitLogCmd = &cobra.Command{
+	Use:   "log <id>",
+	Short: "Show the commits touching a note",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		if full, err := jot.ResolveHistoryID(cfg.Store(), id); err == nil {
+			id = full
+		}
+
+		out, err := jot.GitLog(cfg.StoragePath, id)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		fmt.Print(out)
+	},
+}
+
+// autoCommit commits the files of a note changed by op, if git auto-commit is enabled.
+// Failures are reported as warnings since the note itself has already been saved.
+func autoCommit(op, id string, extra ...string) {
+	if err := jot.CommitNote(cfg, op, id, extra...); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: git commit failed:", err)
+	}
+}
+
+// autoCommitMove commits a note that was moved back into the notes area from the src key to the dest key.
+func autoCommitMove(op, src, dest string) {
+	data, err := cfg.Store().Get(dest)
+	if err != nil {
+		return
+	}
+	if n, err := jot.ParseNote(data, dest); err == nil {
+		autoCommit(op, n.ID, src, dest)
+	}
+}
+
+// autoCommitTemplate commits a template changed by op, if git auto-commit is enabled.
+func autoCommitTemplate(op, name, path string) {
+	if err := jot.GitCommit(cfg, fmt.Sprintf("jot: %s template %s", op, name), path); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: git commit failed:", err)
+	}
+}
+
+// init registers the git commands with the root command.
+func init() {
+	gitCmd.AddCommand(gitLogCmd)
+	rootCmd.AddCommand(gitCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/graph"
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [id]",
+	Short: "Export the graph of notes, links, tags and contexts",
+	Long: `Export the graph of notes, links, tags and contexts as Graphviz DOT, Mermaid or JSON.
+
+When a note ID is given, only nodes within --depth hops of that note are included.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		tagFilter, _ := cmd.Flags().GetStringSlice("tag")
+		contextFilter, _ := cmd.Flags().GetString("context")
+		depth, _ := cmd.Flags().GetInt("depth")
+		withTags, _ := cmd.Flags().GetBool("tags")
+		withContexts, _ := cmd.Flags().GetBool("contexts")
+
+		links, err := jot.LoadLinkGraph(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
+			os.Exit(1)
+		}
+
+		var filtered []*jot.Note
+		for _, n := range links.Notes() {
+			if !jot.HasAllTags(n, tagFilter) {
+				continue
+			}
+			if contextFilter != "" && n.Context != contextFilter {
+				continue
+			}
+			filtered = append(filtered, n)
+		}
+
+		g := graph.Build(filtered, links, graph.Options{
+			Tags:     withTags,
+			Contexts: withContexts,
+		})
+
+		if len(args) > 0 {
+			note, err := jot.FindNoteByID(cfg.Store(), args[0])
+			if err != nil {
+				printNoteError(os.Stderr, err)
+				os.Exit(1)
+			}
+			g = g.Neighbourhood(graph.NoteKey(note.ID), depth)
+		}
+		g.Sort()
+
+		switch format {
+		case "dot":
+			err = g.WriteDOT(os.Stdout)
+		case "mermaid":
+			err = g.WriteMermaid(os.Stdout)
+		case "json":
+			err = g.WriteJSON(os.Stdout)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown format '%s' (use dot, mermaid or json)\n", format)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing graph:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	graphCmd.Flags().String("format", "dot", "Output format: dot, mermaid or json")
+	graphCmd.Flags().StringSlice("tag", nil, "Only include notes with these tag(s)")
+	graphCmd.Flags().String("context", "", "Only include notes in this context")
+	graphCmd.Flags().Int("depth", 1, "Hops around the starting note to include (-1 for unlimited)")
+	graphCmd.Flags().Bool("tags", true, "Include tag nodes")
+	graphCmd.Flags().Bool("contexts", true, "Include context nodes")
+	rootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/diff"
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "List the saved revisions of a note",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := jot.ResolveHistoryID(cfg.Store(), args[0])
+		if err != nil {
+			printNoteError(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		revs, err := jot.ListRevisions(cfg.Store(), id)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading history:", err)
+			os.Exit(1)
+		}
+
+		outputJSON, _ := cmd.Flags().GetBool("json")
+		if outputJSON {
+			if revs == nil {
+				revs = []jot.Revision{}
+			}
+			if err := json.NewEncoder(os.Stdout).Encode(revs); err != nil {
+				fmt.Fprintln(os.Stderr, "Error encoding JSON:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(revs) == 0 {
+			fmt.Printf("No revisions recorded for note %s\n", id)
+			return
+		}
+		for _, r := range revs {
+			summary := ""
+			if text, err := jot.ReadRevision(cfg.Store(), r); err == nil {
+				if n, err := jot.ParseNote([]byte(text), r.Key); err == nil {
+					summary = n.DisplayTitle()
+				}
+			}
+			fmt.Printf("%3d  %s  %s  %s\n", r.Number, r.Name, r.Time.Local().Format("2006-01-02 15:04:05"), summary)
+		}
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <id> [rev] [rev]",
+	Short: "Show changes between revisions of a note",
+	Long: `Show a unified diff between revisions of a note.
+
+With no revision, the previous revision is compared with the current note.
+With one revision, that revision is compared with the current note.
+With two revisions, they are compared with each other.
+Revisions are given by number (see 'jot history') or by a prefix of their name.`,
+	Args: cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := jot.ResolveHistoryID(cfg.Store(), args[0])
+		if err != nil {
+			printNoteError(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		var oldName, newName, oldText, newText string
+		switch len(args) {
+		case 1, 2:
+			newName = id + "@current"
+			newText, err = currentMarkdown(id)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+
+			var rev jot.Revision
+			if len(args) == 2 {
+				rev, err = jot.FindRevision(cfg.Store(), id, args[1])
+			} else {
+				rev, err = previousRevision(id, newText)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			oldName = id + "@" + rev.Name
+			oldText, err = readRevision(rev)
+		case 3:
+			var a, b jot.Revision
+			if a, err = jot.FindRevision(cfg.Store(), id, args[1]); err == nil {
+				b, err = jot.FindRevision(cfg.Store(), id, args[2])
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			oldName, newName = id+"@"+a.Name, id+"@"+b.Name
+			if oldText, err = readRevision(a); err == nil {
+				newText, err = readRevision(b)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		fmt.Print(diff.Unified(oldName, newName, oldText, newText, 3))
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <id> <rev>",
+	Short: "Roll a note back to an earlier revision",
+	Long: `Roll a note back to an earlier revision.
+
+The revision is given by its number as listed by jot history, written as #N or N, or by
+a prefix of its name. A plain number larger than the number of revisions is taken as a name prefix.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := jot.ResolveHistoryID(cfg.Store(), args[0])
+		if err != nil {
+			printNoteError(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		rev, err := jot.FindRevision(cfg.Store(), id, args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		lock := lockNote(id)
+		defer unlock(lock)
+
+		text, err := jot.ReadRevision(cfg.Store(), rev)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading revision:", err)
+			os.Exit(1)
+		}
+		note, err := jot.ParseNote([]byte(text), rev.Key)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading revision:", err)
+			os.Exit(1)
+		}
+
+		// The note may be stored under a name from another title, which saving it moves away from.
+		oldKey, _ := jot.ResolveNoteKey(cfg.Store(), id)
+
+		note.UpdateTimestamp()
+		if err := jot.SaveNote(cfg, note); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving note:", err)
+			os.Exit(1)
+		}
+		autoCommit("restore", note.ID, oldKey)
+		fmt.Printf("Restored note %s to revision %d (%s)\n", note.ID, rev.Number, rev.Name)
+	},
+}
+
+// currentMarkdown returns the markdown of the note with the given ID as it is stored now.
+// A note that no longer exists is treated as empty.
+func currentMarkdown(id string) (string, error) {
+	key, err := jot.ResolveNoteKey(cfg.Store(), id)
+	if err != nil {
+		return "", nil
+	}
+	data, err := cfg.Store().Get(key)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// previousRevision returns the latest revision that differs from the current markdown.
+func previousRevision(id, current string) (jot.Revision, error) {
+	revs, err := jot.ListRevisions(cfg.Store(), id)
+	if err != nil {
+		return jot.Revision{}, err
+	}
+	if len(revs) == 0 {
+		return jot.Revision{}, fmt.Errorf("note ID '%s' has no revisions", id)
+	}
+	for i := len(revs) - 1; i >= 0; i-- {
+		if text, err := readRevision(revs[i]); err == nil && text != current {
+			return revs[i], nil
+		}
+	}
+	return revs[0], nil
+}
+
+// readRevision returns the markdown stored in a revision.
+func readRevision(rev jot.Revision) (string, error) {
+	return jot.ReadRevision(cfg.Store(), rev)
+}
+
+// init registers the history, diff and restore commands with the root command.
+func init() {
+	historyCmd.Flags().Bool("json", false, "Output revisions as JSON")
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage the note index",
+}
+
+var indexRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Discard the note index and rebuild it from the note files",
+	Run: func(cmd *cobra.Command, args []string) {
+		count, err := jot.RebuildIndex(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error rebuilding index:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Indexed %d notes\n", count)
+	},
+}
+
+// init registers the index commands with the root command.
+func init() {
+	indexCmd.AddCommand(indexRebuildCmd)
+	rootCmd.AddCommand(indexCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var linksCmd = &cobra.Command{
+	Use:   "links <id>",
+	Short: "Show outgoing links, backlinks and broken links for a note",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		note, err := jot.FindNoteByID(cfg.Store(), args[0])
+		if err != nil {
+			printNoteError(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		graph, err := jot.LoadLinkGraph(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
+			os.Exit(1)
+		}
+
+		outgoing := graph.Outgoing(note.ID)
+		backlinks := graph.Backlinks(note.ID)
+		broken := graph.Broken(note.ID)
+
+		outputJSON, _ := cmd.Flags().GetBool("json")
+		if outputJSON {
+			out := struct {
+				ID        string     `json:"id"`
+				Outgoing  []jot.Link `json:"outgoing"`
+				Backlinks []jot.Link `json:"backlinks"`
+				Broken    []jot.Link `json:"broken"`
+			}{note.ID, outgoing, backlinks, broken}
+			if out.Outgoing == nil {
+				out.Outgoing = []jot.Link{}
+			}
+			if out.Backlinks == nil {
+				out.Backlinks = []jot.Link{}
+			}
+			if out.Broken == nil {
+				out.Broken = []jot.Link{}
+			}
+			if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+				fmt.Fprintln(os.Stderr, "Error encoding JSON:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		short := jot.AbbreviateIDs(graph.Notes())
+		fmt.Println("Outgoing:")
+		for _, l := range outgoing {
+			switch {
+			case l.IsExternal():
+				fmt.Printf("  %s\n", l.Target)
+			case l.ID != "":
+				fmt.Printf("  %-8s  %s\n", short.Of(l.ID), graph.Note(l.ID).DisplayTitle())
+			}
+		}
+
+		fmt.Println("Backlinks:")
+		for _, l := range backlinks {
+			fmt.Printf("  %-8s  %s\n", short.Of(l.Source), graph.Note(l.Source).DisplayTitle())
+		}
+
+		if len(broken) > 0 {
+			fmt.Println("Broken:")
+			for _, l := range broken {
+				fmt.Printf("  %s\n", l.Target)
+			}
+		}
+	},
+}
+
+func init() {
+	linksCmd.Flags().Bool("json", false, "Output links as JSON")
+	rootCmd.AddCommand(linksCmd)
+}
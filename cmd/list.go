@@ -1,19 +1,33 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/dalryan/jot/internal/jot"
+	"github.com/dalryan/jot/internal/output"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+// watchSettle is how long a watching command waits for a burst of changes, such as a note
+// and its revision being written, to end before refreshing.
+const watchSettle = 200 * time.Millisecond
+
 func init() {
-	listCmd.Flags().StringSlice("tag", nil, "Filter notes by tag(s)")
-	listCmd.Flags().String("context", "", "Override or set the context filter")
-	listCmd.Flags().Bool("json", false, "Output notes as JSON")
+	addFilterFlags(listCmd)
+	listCmd.Flags().Lookup("context").Usage = "Override or set the context filter"
+	listCmd.Flags().String("sort", jot.SortCreated, "Sort by created, updated, title or context")
+	listCmd.Flags().Bool("reverse", false, "Reverse the sort order")
+	listCmd.Flags().Int("limit", 0, "Limit number of results")
+	listCmd.Flags().Int("offset", 0, "Skip this many notes before listing")
+	addOutputFlags(listCmd)
+	listCmd.Flags().Bool("archived", false, "List archived notes instead")
+	listCmd.Flags().Bool("watch", false, "Keep running and list the notes again whenever they change")
 }
 
 var listCmd = &cobra.Command{
@@ -21,65 +35,109 @@ var listCmd = &cobra.Command{
 	Short: "List existing notes",
 	Run: func(cmd *cobra.Command, args []string) {
 		baseDir := cfg.StoragePath
-		filterTags, _ := cmd.Flags().GetStringSlice("tag")
-		filterContext, _ := cmd.Flags().GetString("context")
+		sel := jot.Selection{Filter: filterFlags(cmd)}
+		sel.Sort, _ = cmd.Flags().GetString("sort")
+		sel.Reverse, _ = cmd.Flags().GetBool("reverse")
+		sel.Limit, _ = cmd.Flags().GetInt("limit")
+		sel.Offset, _ = cmd.Flags().GetInt("offset")
+		out := outputFlags(cmd, output.JSON)
 
-		if filterContext == "" {
+		if sel.Context == "" {
 			ctx, err := jot.GetActiveContext(baseDir)
 			if err == nil {
-				filterContext = ctx
+				sel.Context = ctx
 			}
 		}
 
-		notes, err := jot.LoadAllNotes(baseDir)
-		if err != nil {
-			fmt.Println("Error loading notes:", err)
-			return
+		archived, _ := cmd.Flags().GetBool("archived")
+		watch, _ := cmd.Flags().GetBool("watch")
+
+		area := "notes/"
+		if archived {
+			area = "archive/"
+		}
+		printList(sel, out, archived)
+		if watch {
+			clear := out.Format() == output.Text && isTerminal(os.Stdout)
+			watchNotes(area, func() {
+				if clear {
+					fmt.Print("\033[H\033[2J")
+				}
+				printList(sel, out, archived)
+			})
 		}
+	}}
 
-		outputJSON, _ := cmd.Flags().GetBool("json")
+// printList prints the selected notes, or the archived ones.
+func printList(sel jot.Selection, out *output.Printer, archived bool) {
+	var notes []*jot.Note
+	var err error
+	if archived {
+		notes, err = jot.LoadArchivedNotes(cfg.Store())
+	} else {
+		notes, err = jot.LoadAllNotes(cfg.Store())
+	}
+	if err != nil {
+		fmt.Println("Error loading notes:", err)
+		return
+	}
 
-		if outputJSON {
-			if notes == nil {
-				notes = []*jot.Note{}
-			}
-			err = json.NewEncoder(os.Stdout).Encode(notes)
-			if err != nil {
-				fmt.Println("Error encoding JSON:", err)
-				return
-			}
-		} else {
-			for _, n := range notes {
-				if !jot.HasAllTags(n, filterTags) {
-					continue
-				}
-				if filterContext != "" && n.Context != filterContext {
-					continue
-				}
+	selected, err := jot.SelectNotes(notes, sel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
 
-				summary := fmt.Sprintf(
-					"%-8s  %s  %-20s  %s",
-					n.ID[:8],
-					n.CreatedAt.Format("2006-01-02"),
-					fmt.Sprintf("[%s]", joinStrings(n.Tags, ",")),
-					firstLine(n.Content),
-				)
-				fmt.Println(summary)
+	out.Short = jot.AbbreviateIDs(notes)
+	out.Text = func(w io.Writer, i int) error {
+		n := selected[i]
+		_, err := fmt.Fprintf(w,
+			"%-8s  %s  %-20s  %s\n",
+			out.Short.Of(n.ID),
+			n.CreatedAt.Format("2006-01-02"),
+			fmt.Sprintf("[%s]", joinStrings(n.Tags, ",")),
+			n.DisplayTitle(),
+		)
+		return err
+	}
+	if err := out.Print(os.Stdout, selected); err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing output:", err)
+		os.Exit(1)
+	}
+}
+
+// watchNotes calls refresh whenever a document whose key starts with prefix changes, until
+// interrupted. Changes that arrive together cause a single refresh.
+func watchNotes(prefix string, refresh func()) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	events, err := cfg.Store().Watch(ctx, prefix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error watching notes:", err)
+		os.Exit(1)
+	}
+	for range events {
+		settle := time.After(watchSettle)
+	drain:
+		for {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+			case <-settle:
+				break drain
 			}
 		}
-	}}
+		refresh()
+	}
+}
 
 func joinStrings(ss []string, sep string) string {
 	return strings.Join(ss, sep)
 }
 
-func firstLine(s string) string {
-	if idx := strings.IndexByte(s, '\n'); idx != -1 {
-		return s[:idx]
-	}
-	return s
-}
-
 func init() {
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+// lockNote takes the lock for a read-modify-write of the note with the given ID.
+// It exits with an error if another jot process is already working on the note or the vault.
+func lockNote(id string) *jot.Lock {
+	lock, err := jot.LockNote(cfg.StoragePath, id)
+	if err != nil {
+		exitLocked(err)
+	}
+	return lock
+}
+
+// lockVault takes an exclusive lock on the whole vault.
+// It exits with an error if any other jot process is working on the vault.
+func lockVault() *jot.Lock {
+	lock, err := jot.LockVault(cfg.StoragePath)
+	if err != nil {
+		exitLocked(err)
+	}
+	return lock
+}
+
+// lockVaults takes exclusive locks on the vault and the other vaults at the given storage paths.
+// It exits with an error if any other jot process is working on one of them.
+func lockVaults(others ...string) *jot.Lock {
+	lock, err := jot.LockVaults(append([]string{cfg.StoragePath}, others...)...)
+	if err != nil {
+		exitLocked(err)
+	}
+	return lock
+}
+
+// unlock releases a lock, reporting but otherwise ignoring failures.
+func unlock(lock *jot.Lock) {
+	if err := lock.Unlock(); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: failed to release lock:", err)
+	}
+}
+
+// exitLocked reports a locking failure and exits.
+func exitLocked(err error) {
+	if errors.Is(err, jot.ErrLocked) {
+		fmt.Fprintf(os.Stderr, "Error: %v; try again once it has finished\n", err)
+	} else {
+		fmt.Fprintln(os.Stderr, "Error taking lock:", err)
+	}
+	os.Exit(1)
+}
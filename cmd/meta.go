@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var metaCmd = &cobra.Command{
+	Use:   "meta <id> [field] [value]",
+	Short: "Show or change the frontmatter fields of a note",
+	Long: `Show or change the frontmatter fields of a note.
+
+With only an ID, every field is listed. With a field name, that field is printed,
+and with a value as well, the field is set. --unset removes a field instead.
+
+The title, context, tags and links fields are managed by jot; tags and links take a
+comma-separated list. Any other field is stored as extra frontmatter. A note without
+a title field takes its title from its first line of text, without any heading markers.`,
+	Args: cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		unset, _ := cmd.Flags().GetBool("unset")
+
+		note, err := jot.FindNoteByID(cfg.Store(), args[0])
+		if err != nil {
+			printNoteError(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		switch {
+		case len(args) == 1 && !unset:
+			printFields(note)
+			return
+		case len(args) == 2 && !unset:
+			value, ok := note.Field(args[1])
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: note %s has no field '%s'\n", note.ID, args[1])
+				os.Exit(1)
+			}
+			fmt.Println(value)
+			return
+		case unset && len(args) != 2:
+			fmt.Fprintln(os.Stderr, "Error: --unset takes a note ID and a single field name")
+			os.Exit(1)
+		}
+
+		lock := lockNote(note.ID)
+		defer unlock(lock)
+
+		// The note is read again under the lock, so a change saved since it was first read is kept.
+		// The file may be renamed or moved by the change, so the old one is committed too.
+		note, oldKey, err := jot.ResolveNote(cfg.Store(), note.ID)
+		if err != nil {
+			printNoteError(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if unset {
+			err = note.UnsetField(args[1])
+		} else {
+			err = note.SetField(args[1], args[2])
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		note.UpdateTimestamp()
+		if err := jot.SaveNote(cfg, note); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving note:", err)
+			os.Exit(1)
+		}
+		autoCommit("meta", note.ID, oldKey)
+		fmt.Printf("Updated note %s\n", note.ID)
+	},
+}
+
+// printFields lists the frontmatter fields of a note, managed fields first.
+func printFields(n *jot.Note) {
+	for _, key := range []string{"id", "title", "created_at", "updated_at", "context", "tags", "links"} {
+		value, ok := n.Field(key)
+		if key == "title" && !ok {
+			value, ok = n.DisplayTitle()+" (from content)", n.DisplayTitle() != ""
+		}
+		if ok {
+			fmt.Printf("%-12s %s\n", key+":", value)
+		}
+	}
+	for _, key := range n.Extra.Keys() {
+		value, _ := n.Field(key)
+		fmt.Printf("%-12s %s\n", key+":", value)
+	}
+}
+
+// init registers the meta command with the root command.
+func init() {
+	metaCmd.Flags().Bool("unset", false, "Remove the field instead of setting it")
+	rootCmd.AddCommand(metaCmd)
+}
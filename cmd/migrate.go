@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate --to files|log",
+	Short: "Convert the vault to another storage backend",
+	Long: `Convert the vault to another storage backend.
+
+The 'files' backend keeps each note in its own markdown file under the storage path.
+The 'log' backend keeps every note, archived and trashed note and revision in a single
+append-only file, notes.log, which is easier to copy between machines.
+
+Every document is copied byte for byte and checked before the old copy is removed,
+and storage_backend is updated in the config file.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		backend, _ := cmd.Flags().GetString("to")
+		if backend == "" {
+			fmt.Fprintln(os.Stderr, "Error: --to is required (files or log)")
+			os.Exit(1)
+		}
+
+		lock := lockVault()
+		defer unlock(lock)
+
+		count, err := jot.MigrateBackend(cfg, backend)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error migrating:", err)
+			os.Exit(1)
+		}
+		if err := jot.SetConfigValue("storage_backend", backend); err != nil {
+			fmt.Fprintln(os.Stderr, "Error updating config:", err)
+			fmt.Fprintf(os.Stderr, "Set 'storage_backend: %s' in your config file to use the migrated notes.\n", backend)
+			os.Exit(1)
+		}
+
+		var paths []string
+		for _, name := range []string{"notes", "archive", "trash", "history", jot.LogFileName} {
+			paths = append(paths, filepath.Join(cfg.StoragePath, name))
+		}
+		if err := jot.GitCommit(cfg, "jot: migrate to "+backend+" backend", paths...); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: git commit failed:", err)
+		}
+
+		fmt.Printf("Migrated %d document(s) to the %s backend\n", count, backend)
+	},
+}
+
+var migrateFilenamesCmd = &cobra.Command{
+	Use:   "filenames",
+	Short: "Rename and move note files to match the filename format and layout",
+	Long: `Rename and move note files to match the filename format and layout.
+
+With filename_format set to 'id-slug', notes are kept in files named after their ID and
+the slug of their title, such as 1e83df1f-weekly-review.md. The layout setting files notes
+in directories by context ('context'), by month ('date') or by context and year
+('context-date'). This moves existing notes, which are otherwise only moved when they are
+next saved. Pass --format or --layout to change the setting in the config file at the
+same time.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		layout, _ := cmd.Flags().GetString("layout")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if format != "" {
+			if !jot.ValidFilenameFormat(format) {
+				fmt.Fprintf(os.Stderr, "Error: unknown filename format '%s': use '%s' or '%s'\n", format, jot.FilenameID, jot.FilenameIDSlug)
+				os.Exit(1)
+			}
+			cfg.FilenameFormat = format
+		}
+		if layout != "" {
+			if !jot.ValidLayout(layout) {
+				fmt.Fprintf(os.Stderr, "Error: unknown layout '%s': use '%s', '%s', '%s' or '%s'\n", layout, jot.LayoutFlat, jot.LayoutContext, jot.LayoutDate, jot.LayoutContextDate)
+				os.Exit(1)
+			}
+			cfg.Layout = layout
+		}
+
+		lock := lockVault()
+		defer unlock(lock)
+
+		renames, err := jot.RenameNotes(cfg, dryRun)
+		for _, r := range renames {
+			fmt.Printf("%s -> %s\n", r.From, r.To)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error renaming notes:", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			fmt.Printf("Would rename %d note(s)\n", len(renames))
+			return
+		}
+
+		for _, setting := range []struct{ key, value string }{{"filename_format", format}, {"layout", layout}} {
+			if setting.value == "" {
+				continue
+			}
+			if err := jot.SetConfigValue(setting.key, setting.value); err != nil {
+				fmt.Fprintln(os.Stderr, "Error updating config:", err)
+				fmt.Fprintf(os.Stderr, "Set '%s: %s' in your config file to keep the new names.\n", setting.key, setting.value)
+				os.Exit(1)
+			}
+		}
+
+		if len(renames) > 0 {
+			if err := jot.CommitKeys(cfg, "jot: rename note files", "notes"); err != nil {
+				fmt.Fprintln(os.Stderr, "Warning: git commit failed:", err)
+			}
+		}
+		fmt.Printf("Renamed %d note(s)\n", len(renames))
+	},
+}
+
+// init registers the migrate commands with the root command.
+func init() {
+	migrateCmd.Flags().String("to", "", "Storage backend to convert to: files or log")
+	migrateFilenamesCmd.Flags().String("format", "", "Filename format to switch to: id or id-slug")
+	migrateFilenamesCmd.Flags().String("layout", "", "Directory layout to switch to: flat, context, date or context-date")
+	migrateFilenamesCmd.Flags().Bool("dry-run", false, "Show the renames without making them")
+	migrateCmd.AddCommand(migrateFilenamesCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -1,13 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"github.com/dalryan/jot/internal/jot"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +27,10 @@ var newCmd = &cobra.Command{
 		}
 
 		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if err := jot.ValidateTags(tags); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
 		links, _ := cmd.Flags().GetStringSlice("link")
 		templateName, _ := cmd.Flags().GetString("template")
 		explicitContext, _ := cmd.Flags().GetString("context")
@@ -41,11 +45,16 @@ var newCmd = &cobra.Command{
 			}
 		}
 
-		id := uuid.New().String()[:8]
 		now := time.Now()
+		id, err := jot.NewNoteID(cfg, title, now)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error generating note ID:", err)
+			os.Exit(1)
+		}
 
 		note := &jot.Note{
 			ID:        id,
+			Title:     title,
 			CreatedAt: now,
 			UpdatedAt: now,
 			Context:   context,
@@ -54,10 +63,6 @@ var newCmd = &cobra.Command{
 			Content:   "",
 		}
 
-		if title != "" {
-			note.Content = "# " + title + "\n\n"
-		}
-
 		if templateName != "" {
 			content, err := jot.LoadTemplate(cfg, templateName, map[string]string{
 				"date":    time.Now().Format("2006-01-02"),
@@ -88,15 +93,37 @@ var newCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if err := jot.SaveNote(cfg, noteFinal); err != nil {
-			fmt.Fprintln(os.Stderr, "Error saving note:", err)
+		if err := createNote(noteFinal, id, title); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving note: %v; your note is still in %s\n", err, tempPath)
 			os.Exit(1)
 		}
 
+		autoCommit("new", noteFinal.ID)
 		fmt.Printf("Note saved: %s\n", noteFinal.ID)
 	},
 }
 
+// createNote saves a note made by a creating command, refusing to overwrite an existing note.
+// generatedID is the ID the note was created with. If the note still has that ID and another
+// note took it in the meantime, such as while the editor was open, a fresh ID is generated.
+func createNote(note *jot.Note, generatedID, title string) error {
+	lock := lockNote(note.ID)
+	err := jot.CreateNote(cfg, note)
+	unlock(lock)
+	if !errors.Is(err, jot.ErrNoteExists) || note.ID != generatedID {
+		return err
+	}
+
+	id, err := jot.NewNoteID(cfg, title, note.CreatedAt)
+	if err != nil {
+		return err
+	}
+	note.ID = id
+	lock = lockNote(note.ID)
+	defer unlock(lock)
+	return jot.CreateNote(cfg, note)
+}
+
 // init sets up the new command and its flags.
 // This function registers the new command with the root command and
 // defines the available flags for tags, links, context, and template selection.
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dalryan/jot/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// addOutputFlags registers the flags shared by the commands that list notes.
+// --json is kept as a shorthand for --format json.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", output.Text, "Output format: text, json, ndjson, csv, tsv, yaml or a Go template (e.g. '{{.ID}} {{.DisplayTitle}}')")
+	cmd.Flags().StringSlice("columns", nil, "Columns to output: "+strings.Join(output.Columns, ",")+" or any frontmatter field")
+	cmd.Flags().Bool("json", false, "Output notes as JSON (same as --format json)")
+}
+
+// outputFlags returns a printer for the flags registered by addOutputFlags, exiting with an
+// error if the format is invalid. jsonFormat is the format --json stands for.
+func outputFlags(cmd *cobra.Command, jsonFormat string) *output.Printer {
+	format, _ := cmd.Flags().GetString("format")
+	columns, _ := cmd.Flags().GetStringSlice("columns")
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		if cmd.Flags().Changed("format") && format != jsonFormat {
+			fmt.Fprintln(os.Stderr, "Error: --json cannot be combined with --format", format)
+			os.Exit(1)
+		}
+		format = jsonFormat
+	}
+
+	p, err := output.New(format, columns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	return p
+}
+
+// printJSON writes items to stdout as a JSON array, which is empty rather than null when there
+// are none, exiting with an error if they cannot be encoded.
+func printJSON[T any](items []T) {
+	if items == nil {
+		items = []T{}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(items); err != nil {
+		fmt.Fprintln(os.Stderr, "Error encoding JSON:", err)
+		os.Exit(1)
+	}
+}
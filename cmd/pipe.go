@@ -2,9 +2,10 @@ package cmd
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"github.com/dalryan/jot/internal/jot"
+	"github.com/dalryan/jot/internal/output"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -15,9 +16,17 @@ var pipeCmd = &cobra.Command{
 	Use:   "pipe",
 	Short: "Parse note file paths from stdin and display summaries",
 	Run: func(cmd *cobra.Command, args []string) {
-		tagFilter, _ := cmd.Flags().GetStringSlice("tag")
-		contextFilter, _ := cmd.Flags().GetString("context")
+		filter := filterFlags(cmd)
+		out := outputFlags(cmd, output.NDJSON)
 
+		// Short IDs are computed across the vault so they can be passed back to other commands.
+		vault, err := jot.LoadAllNotes(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: could not load notes to abbreviate IDs:", err)
+		}
+		out.Short = jot.AbbreviateIDs(vault)
+
+		var notes []*jot.Note
 		scanner := bufio.NewScanner(os.Stdin)
 		for scanner.Scan() {
 			path := scanner.Text()
@@ -27,39 +36,31 @@ var pipeCmd = &cobra.Command{
 			if err != nil {
 				continue
 			}
-			if !jot.HasAllTags(note, tagFilter) {
-				continue
-			}
-			if contextFilter != "" && note.Context != contextFilter {
-				continue
+			if filter.Matches(note) {
+				notes = append(notes, note)
 			}
+		}
 
-			outputJSON, _ := cmd.Flags().GetBool("json")
-
-			if outputJSON {
-				if note == nil {
-					note = &jot.Note{}
-				}
-				err = json.NewEncoder(os.Stdout).Encode(note)
-				if err != nil {
-					fmt.Println("Error encoding JSON:", err)
-					return
-				}
-			} else {
-				fmt.Printf("🧠 %s  %s  [%s]  %s\n",
-					note.ID[:8],
-					note.CreatedAt.Format("2006-01-02"),
-					jot.JoinTags(note.Tags),
-					jot.FirstLine(note.Content),
-				)
-			}
+		out.Text = func(w io.Writer, i int) error {
+			n := notes[i]
+			_, err := fmt.Fprintf(w, "🧠 %s  %s  [%s]  %s\n",
+				out.Short.Of(n.ID),
+				n.CreatedAt.Format("2006-01-02"),
+				jot.JoinTags(n.Tags),
+				n.DisplayTitle(),
+			)
+			return err
+		}
+		if err := out.Print(os.Stdout, notes); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing output:", err)
+			os.Exit(1)
 		}
 	},
 }
 
 func init() {
-	pipeCmd.Flags().StringSlice("tag", nil, "Filter by tag(s)")
-	pipeCmd.Flags().String("context", "", "Filter by context")
-	pipeCmd.Flags().Bool("json", false, "Output notes as JSON")
+	addFilterFlags(pipeCmd)
+	addOutputFlags(pipeCmd)
+	pipeCmd.Flags().Lookup("json").Usage = "Output one JSON object per note (same as --format ndjson)"
 	rootCmd.AddCommand(pipeCmd)
 }
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/dalryan/jot/internal/jot"
-	"github.com/google/uuid"
 	"io"
 	"os"
 	"strings"
@@ -43,6 +42,10 @@ var quickCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if err := jot.ValidateTags(tags); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
 		links, _ := cmd.Flags().GetStringSlice("link")
 		explicitContext, _ := cmd.Flags().GetString("context")
 
@@ -61,8 +64,13 @@ var quickCmd = &cobra.Command{
 			}
 		}
 
-		id := uuid.New().String()[:8]
 		now := time.Now()
+		title := jot.DeriveTitle(message)
+		id, err := jot.NewNoteID(cfg, title, now)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error generating note ID:", err)
+			os.Exit(1)
+		}
 
 		note := &jot.Note{
 			ID:        id,
@@ -74,12 +82,12 @@ var quickCmd = &cobra.Command{
 			Content:   message,
 		}
 
-		err := jot.SaveNote(cfg, note)
-		if err != nil {
+		if err := createNote(note, id, title); err != nil {
 			fmt.Println("Failed to save note:", err)
 			return
 		}
 
+		autoCommit("quick", note.ID)
 		fmt.Printf("Quick note saved: %s\n", note.ID)
 	},
 }
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+// printNoteError reports a failure to resolve a note reference to w.
+// For an ambiguous reference, the matching notes are listed so the user can pick one.
+func printNoteError(w io.Writer, err error) {
+	fmt.Fprintln(w, "Error:", err)
+
+	var ambiguous *jot.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		fmt.Fprintln(w, "Candidates:")
+		for _, n := range ambiguous.Candidates {
+			fmt.Fprintf(w, "  %-8s  %s\n", n.ID, n.DisplayTitle())
+		}
+	}
+}
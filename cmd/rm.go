@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <id>...",
+	Short: "Move notes to the trash",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		for _, id := range args {
+			note, err := jot.FindNoteByID(cfg.Store(), id)
+			if err != nil {
+				printNoteError(os.Stderr, err)
+				failed = true
+				continue
+			}
+
+			warnBacklinks(note)
+
+			lock := lockNote(note.ID)
+			src, dest, err := jot.TrashNote(cfg.Store(), note.ID)
+			unlock(lock)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error moving note to trash:", err)
+				failed = true
+				continue
+			}
+			autoCommit("rm", note.ID, src, dest)
+			fmt.Printf("Moved note %s to trash\n", note.ID)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// warnBacklinks prints a warning to stderr listing the notes that link to n.
+func warnBacklinks(n *jot.Note) {
+	graph, err := jot.LoadLinkGraph(cfg.Store())
+	if err != nil {
+		return
+	}
+
+	backlinks := graph.Backlinks(n.ID)
+	if len(backlinks) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: note %s is referenced by %d other note(s):\n", n.ID, len(backlinks))
+	for _, l := range backlinks {
+		fmt.Fprintf(os.Stderr, "  %-8s  %s\n", l.Source, graph.Note(l.Source).DisplayTitle())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/dalryan/jot/internal/output"
+	"github.com/dalryan/jot/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search note content, best matches first",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		text := strings.Join(args, " ")
+		filter := filterFlags(cmd)
+		limit, _ := cmd.Flags().GetInt("limit")
+		out := outputFlags(cmd, output.JSON)
+
+		if len(search.QueryTerms(text)) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: query contains no searchable words")
+			os.Exit(1)
+		}
+
+		notes, err := jot.LoadAllNotes(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
+			os.Exit(1)
+		}
+
+		// Results are ranked by relevance, so the selection is only used to filter.
+		filtered, err := jot.SelectNotes(notes, jot.Selection{Filter: filter})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		var highlight func(string) string
+		if out.Format() == output.Text && isTerminal(os.Stdout) {
+			highlight = func(s string) string {
+				return "\033[1;33m" + s + "\033[0m"
+			}
+		}
+
+		results := search.NewIndex(filtered).Search(text, 3, highlight)
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+
+		matched := make([]*jot.Note, len(results))
+		for i, r := range results {
+			matched[i] = r.Note
+		}
+		out.Short = jot.AbbreviateIDs(notes)
+		out.Document = func(i int) any { return results[i] }
+		out.Text = func(w io.Writer, i int) error {
+			r := results[i]
+			if _, err := fmt.Fprintf(w, "%-8s  %s\n", out.Short.Of(r.Note.ID), r.Note.DisplayTitle()); err != nil {
+				return err
+			}
+			for _, s := range r.Snippets {
+				if _, err := fmt.Fprintf(w, "          %s\n", s); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := out.Print(os.Stdout, matched); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing output:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// isTerminal reports whether f is attached to a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
+
+func init() {
+	addFilterFlags(searchCmd)
+	searchCmd.Flags().Int("limit", 0, "Limit number of results")
+	addOutputFlags(searchCmd)
+	searchCmd.Flags().Lookup("json").Usage = "Output results as JSON (same as --format json)"
+	rootCmd.AddCommand(searchCmd)
+}
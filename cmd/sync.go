@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <other-storage-path>",
+	Short: "Two-way sync notes with another jot storage path",
+	Long: `Two-way sync notes with another jot storage path.
+
+New and changed notes are copied in whichever direction they changed, and so is archiving
+or unarchiving a note. Notes deleted on one side since the last sync are moved to the trash
+on the other. Notes changed on both
+sides are merged when the changes do not overlap; otherwise the most recently updated
+version is kept and the other is saved as a conflict copy in both vaults.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		outputJSON, _ := cmd.Flags().GetBool("json")
+
+		// Both vaults are written to, so neither may change underneath the sync.
+		lock := lockVaults(args[0])
+		defer unlock(lock)
+
+		actions, err := jot.SyncVaults(cfg, args[0], dryRun)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error syncing:", err)
+			if len(actions) == 0 {
+				os.Exit(1)
+			}
+		}
+
+		if outputJSON {
+			if actions == nil {
+				actions = []jot.SyncAction{}
+			}
+			if err := json.NewEncoder(os.Stdout).Encode(actions); err != nil {
+				fmt.Fprintln(os.Stderr, "Error encoding JSON:", err)
+				os.Exit(1)
+			}
+		} else {
+			for _, a := range actions {
+				switch a.Kind {
+				case jot.SyncConflict:
+					fmt.Printf("%-8s  %s  (conflict copy: %s)\n", a.Kind, a.ID, a.ConflictID)
+				case jot.SyncTrash:
+					side := "local"
+					if a.Remote {
+						side = "remote"
+					}
+					fmt.Printf("%-8s  %s  (deleted on the other side, moved to %s trash)\n", a.Kind, a.ID, side)
+				default:
+					if a.Archived {
+						fmt.Printf("%-8s  %s  (archived)\n", a.Kind, a.ID)
+					} else {
+						fmt.Printf("%-8s  %s\n", a.Kind, a.ID)
+					}
+				}
+			}
+			if len(actions) == 0 {
+				fmt.Println("Already in sync.")
+			}
+		}
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	syncCmd.Flags().Bool("dry-run", false, "Show what would change without writing anything")
+	syncCmd.Flags().Bool("json", false, "Output actions as JSON")
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/spf13/cobra"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags with note counts, and rename or merge them",
+	Long: `List every tag with the number of notes carrying it, most used first.
+
+The subcommands refactor the tag vocabulary: rename and merge rewrite the tags of every
+affected note, unused lists tags that at most one note carries (one-offs, which are often
+typos, and tags left only on archived or trashed notes), and similar points out tags that
+look like typos or variants of each other, such as go and golang.
+
+Tags are hierarchical: proj/atlas/infra sits under proj/atlas, which sits under proj.
+Filtering by a tag also matches its descendants, --tree shows the hierarchy, and renaming
+or merging a tag moves its descendants along with it.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		filter := filterFlags(cmd)
+		byName, _ := cmd.Flags().GetBool("sort-name")
+		tree, _ := cmd.Flags().GetBool("tree")
+		outputJSON, _ := cmd.Flags().GetBool("json")
+
+		notes, err := jot.LoadAllNotes(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
+			os.Exit(1)
+		}
+		selected, err := jot.SelectNotes(notes, jot.Selection{Filter: filter})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		if tree {
+			printTagTree(jot.TagTree(selected), outputJSON)
+			return
+		}
+
+		tags := jot.CountTags(selected)
+		if byName {
+			sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+		}
+		printTagCounts(tags, outputJSON, "No tags found.")
+	},
+}
+
+var tagsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every note that has it",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		retag(cmd, args[:1], args[1])
+	},
+}
+
+var tagsMergeCmd = &cobra.Command{
+	Use:   "merge <tag>... --into <tag>",
+	Short: "Replace several tags with one on every note that has them",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		into, _ := cmd.Flags().GetString("into")
+		if into == "" {
+			fmt.Fprintln(os.Stderr, "Error: --into is required")
+			os.Exit(1)
+		}
+		retag(cmd, args, into)
+	},
+}
+
+var tagsUnusedCmd = &cobra.Command{
+	Use:   "unused",
+	Short: "List tags that at most one note carries",
+	Long: `List tags that at most one note carries, least used first.
+
+These are one-off tags, which are often typos, and tags that only archived or trashed notes
+still carry, listed with a count of zero. A note tagged proj/atlas counts as carrying proj,
+so a parent tag is not listed while its descendants are in use.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputJSON, _ := cmd.Flags().GetBool("json")
+		s := cfg.Store()
+
+		live, err := jot.LoadAllNotes(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
+			os.Exit(1)
+		}
+		archived, err := jot.LoadArchivedNotes(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading archive:", err)
+			os.Exit(1)
+		}
+		trashed, err := jot.LoadTrashedNotes(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading trash:", err)
+			os.Exit(1)
+		}
+
+		printTagCounts(jot.UnusedTags(live, append(archived, trashed...)), outputJSON, "No unused tags.")
+	},
+}
+
+var tagsSimilarCmd = &cobra.Command{
+	Use:   "similar",
+	Short: "List tags that look like typos or variants of each other",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputJSON, _ := cmd.Flags().GetBool("json")
+
+		notes, err := jot.LoadAllNotes(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
+			os.Exit(1)
+		}
+
+		pairs := jot.SimilarTags(jot.CountTags(notes))
+		if outputJSON {
+			printJSON(pairs)
+			return
+		}
+		if len(pairs) == 0 {
+			fmt.Println("No similar tags.")
+			return
+		}
+		for _, p := range pairs {
+			fmt.Printf("%-20s  %-20s  %s\n",
+				fmt.Sprintf("%s (%d)", p.A.Tag, p.A.Count),
+				fmt.Sprintf("%s (%d)", p.B.Tag, p.B.Count),
+				p.Reason,
+			)
+		}
+		fmt.Println("Merge a pair with: jot tags merge <tag> --into <tag>")
+	},
+}
+
+// retag replaces the from tags with to on every note, printing the changes, or only
+// previewing them with --dry-run.
+func retag(cmd *cobra.Command, from []string, to string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	lock := lockVault()
+	defer unlock(lock)
+
+	changes, err := jot.RetagNotes(cfg, from, to, dryRun)
+	for _, c := range changes {
+		fmt.Printf("%-8s  [%s] -> [%s]  %s\n", c.ID, strings.Join(c.Before, ","), strings.Join(c.After, ","), c.Title)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error retagging notes:", err)
+		os.Exit(1)
+	}
+	if dryRun {
+		fmt.Printf("Would retag %d note(s)\n", len(changes))
+		return
+	}
+
+	if len(changes) > 0 {
+		message := fmt.Sprintf("jot: retag %s as %s", strings.Join(from, ", "), to)
+		if err := jot.CommitKeys(cfg, message, "notes", "history"); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: git commit failed:", err)
+		}
+	}
+	fmt.Printf("Retagged %d note(s)\n", len(changes))
+}
+
+// printTagCounts prints tags with their note counts as a table or JSON, or empty if there are none.
+func printTagCounts(tags []jot.TagCount, outputJSON bool, empty string) {
+	if outputJSON {
+		printJSON(tags)
+		return
+	}
+	if len(tags) == 0 {
+		fmt.Println(empty)
+		return
+	}
+	for _, t := range tags {
+		fmt.Printf("%5d  %s\n", t.Count, t.Tag)
+	}
+}
+
+// printTagTree prints the tag hierarchy with note counts, indenting each level, or as nested JSON.
+func printTagTree(nodes []*jot.TagNode, outputJSON bool) {
+	if outputJSON {
+		printJSON(nodes)
+		return
+	}
+	if len(nodes) == 0 {
+		fmt.Println("No tags found.")
+		return
+	}
+	var walk func(nodes []*jot.TagNode, depth int)
+	walk = func(nodes []*jot.TagNode, depth int) {
+		for _, n := range nodes {
+			fmt.Printf("%5d  %s%s\n", n.Count, strings.Repeat("  ", depth), n.Name)
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(nodes, 0)
+}
+
+// init registers the tags commands with the root command.
+func init() {
+	addFilterFlags(tagsCmd)
+	tagsCmd.Flags().Bool("sort-name", false, "Sort tags by name instead of count")
+	tagsCmd.Flags().Bool("tree", false, "Show tags as a tree of their levels, e.g. proj/atlas/infra")
+	tagsCmd.Flags().Bool("json", false, "Output tags as JSON")
+	tagsUnusedCmd.Flags().Bool("json", false, "Output tags as JSON")
+	tagsSimilarCmd.Flags().Bool("json", false, "Output tag pairs as JSON")
+	tagsRenameCmd.Flags().Bool("dry-run", false, "Show the changes without making them")
+	tagsMergeCmd.Flags().String("into", "", "Tag to merge the tags into")
+	tagsMergeCmd.Flags().Bool("dry-run", false, "Show the changes without making them")
+
+	tagsCmd.AddCommand(tagsRenameCmd)
+	tagsCmd.AddCommand(tagsMergeCmd)
+	tagsCmd.AddCommand(tagsUnusedCmd)
+	tagsCmd.AddCommand(tagsSimilarCmd)
+	rootCmd.AddCommand(tagsCmd)
+}
@@ -56,6 +56,7 @@ var newTemplateCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, "Error running editor:", err)
 			os.Exit(1)
 		}
+		autoCommitTemplate("new", name, templatePath)
 	},
 }
 
@@ -76,6 +77,7 @@ var editTemplateCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, "Error running editor:", err)
 			os.Exit(1)
 		}
+		autoCommitTemplate("edit", name, templatePath)
 	},
 }
 
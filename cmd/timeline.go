@@ -1,12 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/dalryan/jot/internal/jot"
+	"github.com/dalryan/jot/internal/output"
+	"io"
 	"os"
-	"sort"
-	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -15,90 +14,42 @@ var timelineCmd = &cobra.Command{
 	Use:   "timeline",
 	Short: "Show notes in reverse chronological order",
 	Run: func(cmd *cobra.Command, args []string) {
-		tagFilter, _ := cmd.Flags().GetStringSlice("tag")
-		contextFilter, _ := cmd.Flags().GetString("context")
-		sinceStr, _ := cmd.Flags().GetString("since")
-		beforeStr, _ := cmd.Flags().GetString("before")
-		limit, _ := cmd.Flags().GetInt("limit")
+		sel := jot.Selection{Filter: filterFlags(cmd), Sort: jot.SortCreated, Reverse: true}
+		sel.Limit, _ = cmd.Flags().GetInt("limit")
+		out := outputFlags(cmd, output.JSON)
 
-		notes, err := jot.LoadAllNotes(cfg.StoragePath)
+		notes, err := jot.LoadAllNotes(cfg.Store())
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Error loading notes:", err)
 			os.Exit(1)
 		}
 
-		var since, before time.Time
-		if sinceStr != "" {
-			since, _ = parseTime(sinceStr)
-		}
-		if beforeStr != "" {
-			before, _ = parseTime(beforeStr)
-		}
-
-		var filtered []*jot.Note
-		for _, n := range notes {
-			if !jot.HasAllTags(n, tagFilter) {
-				continue
-			}
-			if contextFilter != "" && n.Context != contextFilter {
-				continue
-			}
-			if !since.IsZero() && n.CreatedAt.Before(since) {
-				continue
-			}
-			if !before.IsZero() && n.CreatedAt.After(before) {
-				continue
-			}
-			filtered = append(filtered, n)
+		filtered, err := jot.SelectNotes(notes, sel)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
 		}
-
-		sort.Slice(filtered, func(i, j int) bool {
-			return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
-		})
-
-		if limit > 0 && len(filtered) > limit {
-			filtered = filtered[:limit]
+		out.Short = jot.AbbreviateIDs(notes)
+		out.Text = func(w io.Writer, i int) error {
+			n := filtered[i]
+			_, err := fmt.Fprintf(w, "%-8s  %-16s  %-12s  %s\n",
+				out.Short.Of(n.ID),
+				n.CreatedAt.Format("2006-01-02 15:04"),
+				n.Context,
+				n.DisplayTitle(),
+			)
+			return err
 		}
-
-		outputJSON, _ := cmd.Flags().GetBool("json")
-
-		if outputJSON {
-			if filtered == nil {
-				filtered = []*jot.Note{}
-			}
-			if err := json.NewEncoder(os.Stdout).Encode(filtered); err != nil {
-				fmt.Fprintln(os.Stderr, "Error encoding JSON:", err)
-				os.Exit(1)
-			}
-		} else {
-			for _, n := range filtered {
-				fmt.Printf("%s  %-8s  %-12s  %s\n",
-					n.ID[:8],
-					n.CreatedAt.Format("2006-01-02 15:04"),
-					n.Context,
-					jot.FirstLine(n.Content),
-				)
-			}
+		if err := out.Print(os.Stdout, filtered); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing output:", err)
+			os.Exit(1)
 		}
 	},
 }
 
-func parseTime(input string) (time.Time, error) {
-	if d, err := time.Parse("2006-01-02", input); err == nil {
-		return d, nil
-	}
-	if dur, err := time.ParseDuration(input); err == nil {
-		return time.Now().Add(-dur), nil
-	}
-	return time.Time{}, fmt.Errorf("invalid time format: %s", input)
-}
-
 func init() {
-	timelineCmd.Flags().StringSlice("tag", nil, "Filter by tag(s)")
-	timelineCmd.Flags().String("context", "", "Filter by context")
-	timelineCmd.Flags().String("since", "", "Only notes after (e.g. '7d' or '2025-04-01')")
-	timelineCmd.Flags().String("before", "", "Only notes before a date")
+	addFilterFlags(timelineCmd)
 	timelineCmd.Flags().Int("limit", 0, "Limit number of results")
-	timelineCmd.Flags().Bool("json", false, "Output notes as JSON")
+	addOutputFlags(timelineCmd)
 	rootCmd.AddCommand(timelineCmd)
 }
@@ -20,9 +20,10 @@ var todayCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		today := time.Now().Format("2006-01-02")
-		id := "today-" + time.Now().Format("20060102")
+		now := time.Now()
+		today := now.Format("2006-01-02")
 		title := "Journal for " + today
+		store := cfg.Store()
 
 		contextFlag, _ := cmd.Flags().GetString("context")
 		templateName, _ := cmd.Flags().GetString("template")
@@ -32,23 +33,36 @@ var todayCmd = &cobra.Command{
 			context = "journal"
 		}
 
-		notePath := filepath.Join(cfg.NotesDir(), id+".md")
+		// The journal lock keeps two jot processes from creating today's note at the same time.
+		// Like the note locks, it is only held while looking for the note and while saving it,
+		// never while the editor is open.
+		journalLock := "journal-" + now.Format("20060102")
 
 		// If today's note already exists, just open it
-		if _, err := os.Stat(notePath); err == nil {
-			if err := jot.RunEditor(cfg.Editor, notePath); err != nil {
-				fmt.Fprintln(os.Stderr, "Error running editor:", err)
-				os.Exit(1)
-			}
+		lock := lockNote(journalLock)
+		journal, key, err := jot.FindJournal(store, now)
+		unlock(lock)
+		if err == nil {
+			editStoredNote("today", key, journal.ID, os.Stdin, isTerminal(os.Stdin))
 			return
 		}
 
+		id, err := jot.NewJournalID(cfg, title, now)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error generating note ID:", err)
+			os.Exit(1)
+		}
+
 		note := &jot.Note{
 			ID:        id,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			Title:     title,
+			CreatedAt: now,
+			UpdatedAt: now,
 			Context:   context,
-			Content:   "# " + title + "\n\n",
+		}
+		if err := note.Extra.Set(jot.JournalField, today); err != nil {
+			fmt.Fprintln(os.Stderr, "Error creating note:", err)
+			os.Exit(1)
 		}
 
 		if templateName != "" {
@@ -81,15 +95,41 @@ var todayCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if err := jot.SaveNote(cfg, noteFinal); err != nil {
-			fmt.Fprintln(os.Stderr, "Error saving note:", err)
+		lock = lockNote(journalLock)
+		defer unlock(lock)
+
+		// Someone else may have started today's journal while the editor was open.
+		if journal, _, err := jot.FindJournal(store, now); err == nil {
+			saveJournalConflict(noteFinal, journal.ID, tempPath)
+			return
+		}
+		if err := createNote(noteFinal, id, title); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving note: %v; your note is still in %s\n", err, tempPath)
 			os.Exit(1)
 		}
 
+		autoCommit("today", noteFinal.ID)
 		fmt.Printf("Journal saved: %s\n", noteFinal.ID)
 	},
 }
 
+// saveJournalConflict saves a new journal as a conflict copy of the journal with the given ID,
+// which was created for the same day while the new one was being written.
+func saveJournalConflict(note *jot.Note, id, tempPath string) {
+	note.ID = jot.ConflictID(id)
+	note.Extra.Delete(jot.JournalField)
+	if err := note.Extra.Set("conflict_of", id); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving note: %v; your note is still in %s\n", err, tempPath)
+		os.Exit(1)
+	}
+	if err := createNote(note, note.ID, note.Title); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving note: %v; your note is still in %s\n", err, tempPath)
+		os.Exit(1)
+	}
+	autoCommit("today", note.ID)
+	fmt.Printf("Today's journal %s was started while editing; your version was saved as note %s\n", id, note.ID)
+}
+
 // init sets up the today command and its flags.
 // This function registers the today command with the root command and
 // defines the available flags for context and template selection.
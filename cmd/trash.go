@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/dalryan/jot/internal/jot"
+	"github.com/dalryan/jot/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted notes",
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notes in the trash",
+	Run: func(cmd *cobra.Command, args []string) {
+		out := outputFlags(cmd, output.JSON)
+		notes, err := jot.LoadTrashedNotes(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading trash:", err)
+			os.Exit(1)
+		}
+		if len(notes) == 0 && out.Format() == output.Text {
+			fmt.Println("Trash is empty.")
+			return
+		}
+
+		sort.Slice(notes, func(i, j int) bool {
+			return notes[i].UpdatedAt.After(notes[j].UpdatedAt)
+		})
+		out.Short = jot.AbbreviateIDs(notes)
+		out.Text = func(w io.Writer, i int) error {
+			n := notes[i]
+			_, err := fmt.Fprintf(w, "%-8s  %s  %s\n",
+				out.Short.Of(n.ID),
+				n.CreatedAt.Format("2006-01-02"),
+				n.DisplayTitle(),
+			)
+			return err
+		}
+		if err := out.Print(os.Stdout, notes); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing output:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>...",
+	Short: "Restore notes from the trash",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Restoring checks that no note in the vault has taken the restored note's ID meanwhile.
+		lock := lockVault()
+		defer unlock(lock)
+
+		failed := false
+		for _, id := range args {
+			src, dest, err := jot.RestoreNote(cfg.Store(), id)
+			if err != nil {
+				printNoteError(os.Stderr, err)
+				failed = true
+				continue
+			}
+			autoCommitMove("restore", src, dest)
+			fmt.Printf("Restored note %s\n", id)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete all notes in the trash",
+	Run: func(cmd *cobra.Command, args []string) {
+		lock := lockVault()
+		defer unlock(lock)
+
+		count, err := jot.EmptyTrash(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error emptying trash:", err)
+			os.Exit(1)
+		}
+		if err := jot.CommitKeys(cfg, "jot: empty trash", "trash"); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: git commit failed:", err)
+		}
+		fmt.Printf("Deleted %d note(s) from trash\n", count)
+	},
+}
+
+// init registers the trash commands with the root command.
+func init() {
+	addOutputFlags(trashListCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+}
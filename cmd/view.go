@@ -16,8 +16,6 @@ var viewCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		var note *jot.Note
 		var err error
-		baseDir := cfg.StoragePath
-
 		stat, err := os.Stdin.Stat()
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to read stdin:", err)
@@ -26,24 +24,22 @@ var viewCmd = &cobra.Command{
 
 		if len(args) > 0 {
 			id := args[0]
-			note, err = jot.FindNoteByID(baseDir, id)
+			note, err = jot.FindNoteByID(cfg.Store(), id)
 			if err != nil {
-				fmt.Println("Error:", err)
+				printNoteError(os.Stdout, err)
 				os.Exit(1)
 			}
 		} else if (stat.Mode() & os.ModeCharDevice) == 0 {
 			scanner := bufio.NewScanner(os.Stdin)
 			if scanner.Scan() {
-				line := scanner.Text()
-				if len(line) >= 8 {
-					id := line[:8]
-					note, err = jot.FindNoteByID(baseDir, id)
-					if err != nil {
-						fmt.Println("Error:", err)
-						os.Exit(1)
-					}
-				} else {
-					fmt.Println("Error: Input too short")
+				id := jot.IDFromLine(scanner.Text())
+				if id == "" {
+					fmt.Println("Error: No ID found in input")
+					os.Exit(1)
+				}
+				note, err = jot.FindNoteByID(cfg.Store(), id)
+				if err != nil {
+					printNoteError(os.Stdout, err)
 					os.Exit(1)
 				}
 			} else {
@@ -64,11 +60,18 @@ var viewCmd = &cobra.Command{
 			return
 		}
 
+		graph, err := jot.LoadLinkGraph(cfg.Store())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: could not load notes to resolve links:", err)
+			graph = jot.BuildLinkGraph(nil)
+		}
+
 		if pretty {
-			renderPretty(note)
+			renderPretty(note, graph)
 		} else {
 			renderBasic(note)
 		}
+		renderBacklinks(note, graph, pretty)
 	},
 }
 
@@ -81,6 +84,9 @@ func init() {
 
 func renderBasic(n *jot.Note) {
 	fmt.Printf("# Note: %s\n", n.ID)
+	if n.Title != "" {
+		fmt.Printf("Title:   %s\n", n.Title)
+	}
 	fmt.Printf("Created: %s\n", n.CreatedAt.Format("2006-01-02 15:04"))
 	if len(n.Tags) > 0 {
 		fmt.Printf("Tags:    %s\n", strings.Join(n.Tags, ", "))
@@ -91,9 +97,9 @@ func renderBasic(n *jot.Note) {
 	fmt.Println("\n" + n.Content)
 }
 
-func renderPretty(n *jot.Note) {
+func renderPretty(n *jot.Note, graph *jot.LinkGraph) {
 	// minimal ANSI-styled render
-	fmt.Printf("\033[1m%s\033[0m\n", firstLine(n.Content))
+	fmt.Printf("\033[1m%s\033[0m\n", n.DisplayTitle())
 	fmt.Printf("📅 %s\n", n.CreatedAt.Format("Jan 2 2006, 3:04PM"))
 	if len(n.Tags) > 0 {
 		fmt.Printf("🏷️  %s\n", strings.Join(n.Tags, ", "))
@@ -101,5 +107,50 @@ func renderPretty(n *jot.Note) {
 	if len(n.Links) > 0 {
 		fmt.Printf("🔗 %s\n", strings.Join(n.Links, ", "))
 	}
-	fmt.Println("\n" + n.Content)
+	fmt.Println("\n" + renderWikiLinks(n.Content, graph))
+}
+
+// renderWikiLinks replaces inline [[...]] references with their label, or the title of the
+// note they point to, underlined. References that do not resolve are left as written, dimmed.
+func renderWikiLinks(content string, graph *jot.LinkGraph) string {
+	var sb strings.Builder
+	pos := 0
+	for _, l := range jot.ParseWikiLinks(content) {
+		sb.WriteString(content[pos:l.Start])
+		pos = l.End
+
+		id := graph.Resolve(l.Target)
+		if id == "" {
+			fmt.Fprintf(&sb, "\033[2m%s\033[0m", content[l.Start:l.End])
+			continue
+		}
+
+		text := l.Label
+		if text == "" {
+			text = graph.Note(id).DisplayTitle()
+		}
+		if text == "" {
+			text = id
+		}
+		fmt.Fprintf(&sb, "\033[4;36m%s\033[0m", text)
+	}
+	sb.WriteString(content[pos:])
+	return sb.String()
+}
+
+// renderBacklinks prints the notes that link to n, if there are any.
+func renderBacklinks(n *jot.Note, graph *jot.LinkGraph, pretty bool) {
+	backlinks := graph.Backlinks(n.ID)
+	if len(backlinks) == 0 {
+		return
+	}
+
+	if pretty {
+		fmt.Println("\n\033[1mBacklinks\033[0m")
+	} else {
+		fmt.Println("\n## Backlinks")
+	}
+	for _, l := range backlinks {
+		fmt.Printf("- %s  %s\n", l.Source, graph.Note(l.Source).DisplayTitle())
+	}
 }
@@ -3,7 +3,6 @@ module github.com/dalryan/jot
 go 1.24.0
 
 require (
-	github.com/google/uuid v1.6.0
 	github.com/spf13/cobra v1.10.2
 	gopkg.in/yaml.v3 v3.0.1
 )
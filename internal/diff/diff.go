@@ -0,0 +1,205 @@
+// Package diff computes line-level differences between texts and renders them as unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of change applied to a line.
+type Op int
+
+const (
+	// Equal marks a line present in both texts.
+	Equal Op = iota
+	// Delete marks a line only present in the old text.
+	Delete
+	// Insert marks a line only present in the new text.
+	Insert
+)
+
+// Line is a single line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines computes the shortest edit script turning the old lines into the new lines,
+// using Myers' O(ND) algorithm.
+func Lines(a, b []string) []Line {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil
+	}
+
+	offset := total
+	v := make([]int, 2*total+2)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= total && !found; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script.
+	var out []Line
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			out = append(out, Line{Op: Equal, Text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				out = append(out, Line{Op: Insert, Text: b[y]})
+			} else {
+				x--
+				out = append(out, Line{Op: Delete, Text: a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// SplitLines splits text into lines, ignoring a single trailing newline.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Unified renders the differences between two texts as a unified diff with the given
+// number of context lines. Returns an empty string if the texts are identical.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	lines := Lines(SplitLines(oldText), SplitLines(newText))
+
+	changed := false
+	for _, l := range lines {
+		if l.Op != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	// oldLine and newLine hold the 1-based line numbers reached before index i.
+	oldLine, newLine := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	oldLine[0], newLine[0] = 1, 1
+	for i, l := range lines {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if l.Op != Insert {
+			oldLine[i+1]++
+		}
+		if l.Op != Delete {
+			newLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Op == Equal {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			end = run
+		}
+
+		oldCount, newCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.Op != Insert {
+				oldCount++
+			}
+			if l.Op != Delete {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldLine[start], oldCount), hunkRange(newLine[start], newCount))
+		for _, l := range lines[start:end] {
+			switch l.Op {
+			case Equal:
+				sb.WriteString(" ")
+			case Delete:
+				sb.WriteString("-")
+			case Insert:
+				sb.WriteString("+")
+			}
+			sb.WriteString(l.Text)
+			sb.WriteByte('\n')
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats a hunk header range the way diff -u does.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,133 @@
+package diff
+
+// hunk is a change to a range of base lines: base[Start:End] is replaced by Lines.
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// hunks converts an edit script between base and a changed text into hunks over base.
+func hunks(edits []Line) []hunk {
+	var out []hunk
+	pos := 0
+	var cur *hunk
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			if cur != nil {
+				out = append(out, *cur)
+				cur = nil
+			}
+			pos++
+		case Delete:
+			if cur == nil {
+				cur = &hunk{start: pos, end: pos}
+			}
+			cur.end++
+			pos++
+		case Insert:
+			if cur == nil {
+				cur = &hunk{start: pos, end: pos}
+			}
+			cur.lines = append(cur.lines, e.Text)
+		}
+	}
+	if cur != nil {
+		out = append(out, *cur)
+	}
+	return out
+}
+
+// apply returns base[start:end] with the given hunks, which must lie inside that range, applied.
+func apply(base []string, start, end int, hs []hunk) []string {
+	var out []string
+	pos := start
+	for _, h := range hs {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.lines...)
+		pos = h.end
+	}
+	return append(out, base[pos:end]...)
+}
+
+// Merge3 performs a line-based three-way merge of two texts derived from a common base.
+// Changes that touch separate parts of the base are combined; identical changes are kept once.
+// Overlapping, differing changes are written between conflict markers labelled with
+// oursLabel and theirsLabel, and the returned flag is true.
+func Merge3(base, ours, theirs []string, oursLabel, theirsLabel string) ([]string, bool) {
+	a := hunks(Lines(base, ours))
+	b := hunks(Lines(base, theirs))
+
+	var out []string
+	conflict := false
+	pos := 0
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		// Start a region at the earliest pending hunk and grow it while hunks from
+		// either side overlap or touch it.
+		start := len(base)
+		if i < len(a) {
+			start = a[i].start
+		}
+		if j < len(b) && b[j].start < start {
+			start = b[j].start
+		}
+		end := start
+		ai, bj := i, j
+		for {
+			grew := false
+			if ai < len(a) && a[ai].start <= end {
+				end = max(end, a[ai].end)
+				ai++
+				grew = true
+			}
+			if bj < len(b) && b[bj].start <= end {
+				end = max(end, b[bj].end)
+				bj++
+				grew = true
+			}
+			if !grew {
+				break
+			}
+		}
+
+		out = append(out, base[pos:start]...)
+		oursRegion := apply(base, start, end, a[i:ai])
+		theirsRegion := apply(base, start, end, b[j:bj])
+
+		switch {
+		case bj == j:
+			out = append(out, oursRegion...)
+		case ai == i:
+			out = append(out, theirsRegion...)
+		case equalLines(oursRegion, theirsRegion):
+			out = append(out, oursRegion...)
+		default:
+			conflict = true
+			out = append(out, "<<<<<<< "+oursLabel)
+			out = append(out, oursRegion...)
+			out = append(out, "=======")
+			out = append(out, theirsRegion...)
+			out = append(out, ">>>>>>> "+theirsLabel)
+		}
+
+		pos = end
+		i, j = ai, bj
+	}
+
+	return append(out, base[pos:]...), conflict
+}
+
+// equalLines reports whether two line slices are identical.
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
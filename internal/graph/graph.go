@@ -0,0 +1,261 @@
+// Package graph builds a graph of notes, tags and contexts and exports it for visualisation.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+// Node kinds.
+const (
+	KindNote    = "note"
+	KindTag     = "tag"
+	KindContext = "context"
+)
+
+// Edge kinds.
+const (
+	EdgeLink    = "link"
+	EdgeTag     = "tag"
+	EdgeContext = "context"
+)
+
+// Node is a note, tag or context in the graph.
+type Node struct {
+	// Key uniquely identifies the node, e.g. "note:abc12345" or "tag:go".
+	Key string `json:"key"`
+	// Kind is one of KindNote, KindTag or KindContext.
+	Kind string `json:"kind"`
+	// Label is the human-readable name of the node.
+	Label string `json:"label"`
+}
+
+// Edge connects two nodes by key.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Kind is one of EdgeLink, EdgeTag or EdgeContext.
+	Kind string `json:"kind"`
+}
+
+// Graph is a set of nodes and the edges between them.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Options controls which relationships are included when building a graph.
+type Options struct {
+	// Tags adds a node per tag, connected to every note carrying it.
+	Tags bool
+	// Contexts adds a node per context, connected to every note in it.
+	Contexts bool
+}
+
+// NoteKey returns the graph key of the note with the given ID.
+func NoteKey(id string) string {
+	return KindNote + ":" + id
+}
+
+// Build creates a graph from the given notes. Links between notes are taken from
+// the link graph, which resolves both frontmatter links and inline references;
+// links to notes outside the given set are dropped.
+func Build(notes []*jot.Note, links *jot.LinkGraph, opts Options) *Graph {
+	g := &Graph{}
+	included := make(map[string]bool)
+	added := make(map[string]bool)
+
+	addNode := func(key, kind, label string) {
+		if !added[key] {
+			added[key] = true
+			g.Nodes = append(g.Nodes, Node{Key: key, Kind: kind, Label: label})
+		}
+	}
+
+	for _, n := range notes {
+		included[n.ID] = true
+		label := n.DisplayTitle()
+		if label == "" {
+			label = n.ID
+		}
+		addNode(NoteKey(n.ID), KindNote, label)
+	}
+
+	for _, n := range notes {
+		linked := make(map[string]bool)
+		for _, l := range links.Outgoing(n.ID) {
+			if l.ID == "" || l.ID == n.ID || !included[l.ID] || linked[l.ID] {
+				continue
+			}
+			linked[l.ID] = true
+			g.Edges = append(g.Edges, Edge{From: NoteKey(n.ID), To: NoteKey(l.ID), Kind: EdgeLink})
+		}
+
+		if opts.Tags {
+			for _, t := range n.Tags {
+				key := KindTag + ":" + t
+				addNode(key, KindTag, "#"+t)
+				g.Edges = append(g.Edges, Edge{From: NoteKey(n.ID), To: key, Kind: EdgeTag})
+			}
+		}
+
+		if opts.Contexts && n.Context != "" {
+			key := KindContext + ":" + n.Context
+			addNode(key, KindContext, "@"+n.Context)
+			g.Edges = append(g.Edges, Edge{From: NoteKey(n.ID), To: key, Kind: EdgeContext})
+		}
+	}
+
+	return g
+}
+
+// Neighbourhood returns the subgraph of nodes within depth hops of the start node,
+// following edges in either direction. A negative depth returns the whole graph.
+func (g *Graph) Neighbourhood(start string, depth int) *Graph {
+	if depth < 0 {
+		return g
+	}
+
+	adjacent := make(map[string][]string)
+	for _, e := range g.Edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+		adjacent[e.To] = append(adjacent[e.To], e.From)
+	}
+
+	keep := map[string]bool{start: true}
+	frontier := []string{start}
+	for d := 0; d < depth && len(frontier) > 0; d++ {
+		var next []string
+		for _, key := range frontier {
+			for _, adj := range adjacent[key] {
+				if !keep[adj] {
+					keep[adj] = true
+					next = append(next, adj)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sub := &Graph{}
+	for _, n := range g.Nodes {
+		if keep[n.Key] {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if keep[e.From] && keep[e.To] {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph jot {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&sb, "  %s [label=%s, shape=%s];\n", dotQuote(n.Key), dotQuote(n.Label), dotShape(n.Kind))
+	}
+	for _, e := range g.Edges {
+		style := "solid"
+		if e.Kind != EdgeLink {
+			style = "dashed"
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [style=%s];\n", dotQuote(e.From), dotQuote(e.To), style)
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.Key] = fmt.Sprintf("n%d", i)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	for _, n := range g.Nodes {
+		label := mermaidQuote(n.Label)
+		switch n.Kind {
+		case KindTag:
+			fmt.Fprintf(&sb, "  %s([%s])\n", ids[n.Key], label)
+		case KindContext:
+			fmt.Fprintf(&sb, "  %s{{%s}}\n", ids[n.Key], label)
+		default:
+			fmt.Fprintf(&sb, "  %s[%s]\n", ids[n.Key], label)
+		}
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind != EdgeLink {
+			arrow = "-.-"
+		}
+		fmt.Fprintf(&sb, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteJSON writes the graph as a JSON document with "nodes" and "edges" arrays.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	out := *g
+	if out.Nodes == nil {
+		out.Nodes = []Node{}
+	}
+	if out.Edges == nil {
+		out.Edges = []Edge{}
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+// Sort orders nodes by kind and key, and edges by endpoints, for stable output.
+func (g *Graph) Sort() {
+	rank := map[string]int{KindNote: 0, KindTag: 1, KindContext: 2}
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		if rank[g.Nodes[i].Kind] != rank[g.Nodes[j].Kind] {
+			return rank[g.Nodes[i].Kind] < rank[g.Nodes[j].Kind]
+		}
+		return g.Nodes[i].Key < g.Nodes[j].Key
+	})
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+}
+
+// dotQuote quotes a string for use as a DOT identifier or attribute value.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// dotShape returns the DOT node shape used for a node kind.
+func dotShape(kind string) string {
+	switch kind {
+	case KindTag:
+		return "ellipse"
+	case KindContext:
+		return "hexagon"
+	default:
+		return "box"
+	}
+}
+
+// mermaidQuote quotes a node label for Mermaid, escaping characters that break its syntax.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -1,6 +1,7 @@
 package jot
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,18 +21,45 @@ type Config struct {
 
 	// StoragePath specifies the base directory for storing notes and templates.
 	StoragePath string `yaml:"storage_path"`
+
+	// StorageBackend selects how notes are kept in the storage path: BackendFiles (the default)
+	// or BackendLog.
+	StorageBackend string `yaml:"storage_backend,omitempty"`
+
+	// IDScheme selects how IDs of new notes are generated: IDHex (the default), IDULID,
+	// IDZettel or IDSlug.
+	IDScheme string `yaml:"id_scheme,omitempty"`
+
+	// FilenameFormat selects how note files are named: FilenameID (the default) or FilenameIDSlug.
+	FilenameFormat string `yaml:"filename_format,omitempty"`
+
+	// Layout selects the directories notes are kept in: LayoutFlat (the default), LayoutContext,
+	// LayoutDate or LayoutContextDate.
+	Layout string `yaml:"layout,omitempty"`
+
+	// HistoryLimit is the number of revisions kept for each note; older ones are pruned when the
+	// note is saved. Zero keeps DefaultHistoryLimit revisions and a negative value keeps all.
+	HistoryLimit int `yaml:"history_limit,omitempty"`
+
+	// SyncLinks adds notes referenced inline with [[...]] to the frontmatter links list when a note is saved.
+	SyncLinks bool `yaml:"sync_links,omitempty"`
+
+	// Git configures the optional git integration for the storage path.
+	Git GitConfig `yaml:"git,omitempty"`
+
+	// store is the store notes are read from and written to, created on first use.
+	store Store
 }
 
 // LoadConfig loads the configuration from the config file.
 // If the config file doesn't exist, default values are returned.
 // If the config file exists but is invalid, an error is returned.
 func LoadConfig() (*Config, error) {
-	home, err := os.UserHomeDir()
+	configPath, err := ConfigPath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory for config initialization: %w", err)
+		return nil, err
 	}
-	configDir := filepath.Join(home, ".jot")
-	configPath := filepath.Join(configDir, "config.yaml")
+	configDir := filepath.Dir(configPath)
 
 	cfg := &Config{
 		Editor:         "vi",
@@ -64,6 +92,68 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// ConfigPath returns the path of the config file, ~/.jot/config.yaml.
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory for config initialization: %w", err)
+	}
+	return filepath.Join(home, ".jot", "config.yaml"), nil
+}
+
+// SetConfigValue sets a top-level setting in the config file to value, creating the file if needed.
+// Unlike SaveConfig, it keeps the rest of the file, including comments, as it is.
+func SetConfigValue(key, value string) error {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file at path '%s': %w", configPath, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file at path '%s': %w", configPath, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("invalid config file at path '%s': expected a YAML mapping", configPath)
+	}
+
+	found := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+			found = true
+		}
+	}
+	if !found {
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+		)
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to marshal config data to YAML: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory at path '%s': %w", filepath.Dir(configPath), err)
+	}
+	if err := WriteFileAtomic(configPath, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file to path '%s': %w", configPath, err)
+	}
+	return nil
+}
+
 // SaveConfig saves the configuration to the config file.
 func (c *Config) SaveConfig() error {
 	configDir := filepath.Dir(filepath.Join(c.StoragePath, "config.yaml"))
@@ -77,7 +167,7 @@ func (c *Config) SaveConfig() error {
 	}
 
 	configPath := filepath.Join(configDir, "config.yaml")
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := WriteFileAtomic(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file to path '%s': %w", configPath, err)
 	}
 
@@ -92,9 +182,48 @@ func (c *Config) Validate() error {
 	if c.StoragePath == "" {
 		return fmt.Errorf("storage path cannot be empty")
 	}
+	switch c.StorageBackend {
+	case "", BackendFiles, BackendLog:
+	default:
+		return fmt.Errorf("unknown storage backend '%s': use '%s' or '%s'", c.StorageBackend, BackendFiles, BackendLog)
+	}
+	if !ValidIDScheme(c.IDScheme) {
+		return fmt.Errorf("unknown ID scheme '%s': use '%s', '%s', '%s' or '%s'", c.IDScheme, IDHex, IDULID, IDZettel, IDSlug)
+	}
+	if !ValidFilenameFormat(c.FilenameFormat) {
+		return fmt.Errorf("unknown filename format '%s': use '%s' or '%s'", c.FilenameFormat, FilenameID, FilenameIDSlug)
+	}
+	if !ValidLayout(c.Layout) {
+		return fmt.Errorf("unknown layout '%s': use '%s', '%s', '%s' or '%s'", c.Layout, LayoutFlat, LayoutContext, LayoutDate, LayoutContextDate)
+	}
 	return nil
 }
 
+// Store returns the store holding the vault's notes.
+// Unless another store was set with UseStore, it is the store for the configured backend in the storage path.
+func (c *Config) Store() Store {
+	if c.store == nil {
+		c.store = NewStore(c.StoragePath, c.StorageBackend)
+	}
+	return c.store
+}
+
+// revisionsKept returns the number of revisions kept for each note, or zero to keep them all.
+func (c *Config) revisionsKept() int {
+	switch {
+	case c.HistoryLimit == 0:
+		return DefaultHistoryLimit
+	case c.HistoryLimit < 0:
+		return 0
+	}
+	return c.HistoryLimit
+}
+
+// UseStore makes every operation on the vault go through s instead of the storage path.
+func (c *Config) UseStore(s Store) {
+	c.store = s
+}
+
 // NotesDir returns the path to the notes directory.
 func (c *Config) NotesDir() string {
 	return filepath.Join(c.StoragePath, "notes")
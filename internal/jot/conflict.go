@@ -0,0 +1,70 @@
+package jot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dalryan/jot/internal/diff"
+)
+
+// ConflictID returns the ID for a conflict copy of the note with the given ID.
+func ConflictID(id string) string {
+	return fmt.Sprintf("%s-conflict-%s", id, time.Now().Format("20060102150405"))
+}
+
+// ConflictCopy parses a conflicting version of a note and returns it under copyID,
+// with a conflict_of field pointing back at the original note.
+func ConflictCopy(data []byte, id, copyID string) (*Note, error) {
+	n, err := ParseNote(data, id)
+	if err != nil {
+		return nil, err
+	}
+	n.ID = copyID
+	if err := n.Extra.Set("conflict_of", id); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// MergeMarkdown performs a line-based three-way merge of two versions of a note's markdown
+// against their common base. The updated_at timestamps are first aligned to the newest of the
+// two versions so they never conflict. If the changes overlap, the result contains conflict
+// markers labelled oursLabel and theirsLabel, and the returned flag is true.
+func MergeMarkdown(base, ours, theirs []byte, oursLabel, theirsLabel string) ([]byte, bool) {
+	var newest time.Time
+	var stamp string
+	for _, data := range [][]byte{ours, theirs} {
+		line := updatedAtLine.Find(data)
+		if line == nil {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(strings.TrimPrefix(string(line), "updated_at:")), `"'`)
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err == nil && t.After(newest) {
+			newest, stamp = t, string(line)
+		}
+	}
+
+	align := func(data []byte) []string {
+		if stamp == "" {
+			return diff.SplitLines(string(data))
+		}
+		return diff.SplitLines(updatedAtLine.ReplaceAllLiteralString(string(data), stamp))
+	}
+
+	merged, conflict := diff.Merge3(align(base), align(ours), align(theirs), oursLabel, theirsLabel)
+	return []byte(strings.Join(merged, "\n") + "\n"), conflict
+}
+
+// Ways of resolving a note that was changed by someone else while it was being edited.
+const (
+	// ResolveMerge merges both sets of changes line by line.
+	ResolveMerge = "merge"
+	// ResolveMine keeps the edited version and discards the other changes.
+	ResolveMine = "mine"
+	// ResolveTheirs keeps the other changes and discards the edit.
+	ResolveTheirs = "theirs"
+	// ResolveCopy keeps the other changes and saves the edit as a conflict copy.
+	ResolveCopy = "copy"
+)
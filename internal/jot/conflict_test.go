@@ -0,0 +1,79 @@
+package jot
+
+import (
+	"strings"
+	"testing"
+)
+
+// markdown builds a note file with the given updated_at time and body lines.
+func markdown(updated string, body ...string) []byte {
+	return []byte("---\nid: abc\nupdated_at: " + updated + "\n---\n" + strings.Join(body, "\n") + "\n")
+}
+
+func TestMergeMarkdown(t *testing.T) {
+	base := markdown("2026-01-01T10:00:00Z", "one", "two", "three", "four", "five")
+
+	tests := []struct {
+		name         string
+		ours, theirs []byte
+		want         []byte
+		conflict     bool
+	}{
+		{
+			name:   "separate changes are combined",
+			ours:   markdown("2026-01-02T10:00:00Z", "ONE", "two", "three", "four", "five"),
+			theirs: markdown("2026-01-03T10:00:00Z", "one", "two", "three", "four", "FIVE"),
+			want:   markdown("2026-01-03T10:00:00Z", "ONE", "two", "three", "four", "FIVE"),
+		},
+		{
+			name:   "identical changes are kept once",
+			ours:   markdown("2026-01-03T10:00:00Z", "one", "two", "THREE", "four", "five"),
+			theirs: markdown("2026-01-02T10:00:00Z", "one", "two", "THREE", "four", "five"),
+			want:   markdown("2026-01-03T10:00:00Z", "one", "two", "THREE", "four", "five"),
+		},
+		{
+			name:   "one side unchanged takes the other",
+			ours:   base,
+			theirs: markdown("2026-01-02T10:00:00Z", "one", "two", "four", "five", "six"),
+			want:   markdown("2026-01-02T10:00:00Z", "one", "two", "four", "five", "six"),
+		},
+		{
+			name:     "overlapping changes conflict",
+			ours:     markdown("2026-01-02T10:00:00Z", "one", "two", "mine", "four", "five"),
+			theirs:   markdown("2026-01-03T10:00:00Z", "one", "two", "theirs", "four", "five"),
+			want:     markdown("2026-01-03T10:00:00Z", "one", "two", "<<<<<<< ours", "mine", "=======", "theirs", ">>>>>>> theirs", "four", "five"),
+			conflict: true,
+		},
+		{
+			name:     "deleting a line someone else changed conflicts",
+			ours:     markdown("2026-01-02T10:00:00Z", "one", "three", "four", "five"),
+			theirs:   markdown("2026-01-02T10:00:00Z", "one", "TWO", "three", "four", "five"),
+			want:     markdown("2026-01-02T10:00:00Z", "one", "<<<<<<< ours", "=======", "TWO", ">>>>>>> theirs", "three", "four", "five"),
+			conflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := MergeMarkdown(base, tt.ours, tt.theirs, "ours", "theirs")
+			if conflict != tt.conflict {
+				t.Errorf("conflict = %v, want %v", conflict, tt.conflict)
+			}
+			if string(got) != string(tt.want) {
+				t.Errorf("merged =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConflictCopy(t *testing.T) {
+	n, err := ConflictCopy(markdown("2026-01-02T10:00:00Z", "body"), "abc", "abc-conflict-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ID != "abc-conflict-1" {
+		t.Errorf("ID = %q, want abc-conflict-1", n.ID)
+	}
+	if v, _ := n.Field("conflict_of"); v != "abc" {
+		t.Errorf("conflict_of = %q, want abc", v)
+	}
+}
@@ -21,7 +21,7 @@ func GetActiveContext(baseDir string) (string, error) {
 // It takes the base directory path and the context name as input.
 // Returns an error if the file cannot be written.
 func SetActiveContext(baseDir, name string) error {
-	return os.WriteFile(filepath.Join(baseDir, "context"), []byte(name+"\n"), 0644)
+	return WriteFileAtomic(filepath.Join(baseDir, "context"), []byte(name+"\n"), 0644)
 }
 
 // ClearContext removes the context file, effectively clearing the active context.
@@ -1,22 +1,209 @@
 package jot
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
-// HasAllTags checks if a note contains all the specified tags.
-// It returns true if the note has all the tags in the provided list, or if the list is empty.
-// Returns false if any tag is missing from the note.
-func HasAllTags(note *Note, tags []string) bool {
-	if len(tags) == 0 {
+// Sort orders that can be given to SelectNotes.
+const (
+	// SortCreated orders notes by creation time, oldest first. It is the default.
+	SortCreated = "created"
+	// SortUpdated orders notes by last update, least recently updated first.
+	SortUpdated = "updated"
+	// SortTitle orders notes alphabetically by title, ignoring case.
+	SortTitle = "title"
+	// SortContext orders notes alphabetically by context, then by creation time.
+	SortContext = "context"
+)
+
+// Filter selects notes by their metadata. The zero Filter matches every note.
+type Filter struct {
+	// Tags the note must all have.
+	Tags []string
+	// Context the note must be in, if set.
+	Context string
+	// Fields the note's extra frontmatter must match, as for HasAllFields.
+	Fields []string
+	// Since and Before bound the creation time of the note, if set.
+	Since, Before time.Time
+	// Match, if set, must also accept the note. It is used for parsed --query expressions.
+	Match func(*Note) bool
+}
+
+// Matches reports whether the note passes every part of the filter.
+func (f Filter) Matches(n *Note) bool {
+	switch {
+	case !HasAllTags(n, f.Tags):
+		return false
+	case f.Context != "" && n.Context != f.Context:
+		return false
+	case !HasAllFields(n, f.Fields):
+		return false
+	case !f.Since.IsZero() && n.CreatedAt.Before(f.Since):
+		return false
+	case !f.Before.IsZero() && n.CreatedAt.After(f.Before):
+		return false
+	case f.Match != nil && !f.Match(n):
+		return false
+	}
+	return true
+}
+
+// Selection describes which notes a listing shows and in what order.
+type Selection struct {
+	Filter
+	// Sort is one of the Sort orders; empty means SortCreated.
+	Sort string
+	// Reverse flips the sort order.
+	Reverse bool
+	// Offset skips that many notes after sorting, and Limit keeps at most that many (0 for all).
+	Offset, Limit int
+}
+
+// ValidSort reports whether order names a known sort order. The empty order means SortCreated.
+func ValidSort(order string) bool {
+	switch order {
+	case "", SortCreated, SortUpdated, SortTitle, SortContext:
 		return true
 	}
+	return false
+}
+
+// SelectNotes filters, sorts and pages notes as described by sel, leaving notes untouched.
+// Notes that compare equal keep a stable order by ID, so repeated listings agree.
+func SelectNotes(notes []*Note, sel Selection) ([]*Note, error) {
+	if !ValidSort(sel.Sort) {
+		return nil, fmt.Errorf("unknown sort order '%s': use '%s', '%s', '%s' or '%s'", sel.Sort, SortCreated, SortUpdated, SortTitle, SortContext)
+	}
+	if sel.Offset < 0 || sel.Limit < 0 {
+		return nil, fmt.Errorf("offset and limit must not be negative")
+	}
 
-	tagSet := make(map[string]bool)
-	for _, t := range note.Tags {
-		tagSet[t] = true
+	selected := []*Note{}
+	for _, n := range notes {
+		if sel.Matches(n) {
+			selected = append(selected, n)
+		}
 	}
 
+	less := noteOrder(sel.Sort)
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		if sel.Reverse {
+			a, b = b, a
+		}
+		if c := less(a, b); c != 0 {
+			return c < 0
+		}
+		return a.ID < b.ID
+	})
+
+	selected = selected[min(sel.Offset, len(selected)):]
+	if sel.Limit > 0 && len(selected) > sel.Limit {
+		selected = selected[:sel.Limit]
+	}
+	return selected, nil
+}
+
+// noteOrder returns a comparison of two notes for the sort order.
+func noteOrder(order string) func(a, b *Note) int {
+	byCreated := func(a, b *Note) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	switch order {
+	case SortUpdated:
+		return func(a, b *Note) int { return a.UpdatedAt.Compare(b.UpdatedAt) }
+	case SortTitle:
+		return func(a, b *Note) int {
+			return strings.Compare(strings.ToLower(a.DisplayTitle()), strings.ToLower(b.DisplayTitle()))
+		}
+	case SortContext:
+		return func(a, b *Note) int {
+			if c := strings.Compare(a.Context, b.Context); c != 0 {
+				return c
+			}
+			return byCreated(a, b)
+		}
+	}
+	return byCreated
+}
+
+// relativeTime matches a relative time such as 7d or 2w: a number of days, weeks or years.
+var relativeTime = regexp.MustCompile(`^(\d+)([dwy])$`)
+
+// ParseTime parses a point in time given on the command line: a date (2006-01-02), taken as
+// midnight UTC, or a time relative to now as accepted by ParseAgo.
+func ParseTime(input string, now time.Time) (time.Time, error) {
+	if d, err := time.Parse("2006-01-02", input); err == nil {
+		return d, nil
+	}
+	if t, ok := ParseAgo(input, now); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s': use a date like 2026-01-02 or a relative time like 7d", input)
+}
+
+// ParseAgo parses a time relative to now, either a number of days, weeks or years (7d, 2w, 1y)
+// or a non-negative Go duration (36h), and returns the point in time that long before now.
+func ParseAgo(input string, now time.Time) (time.Time, bool) {
+	if m := relativeTime.FindStringSubmatch(input); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, false
+		}
+		switch m[2] {
+		case "d":
+			return now.AddDate(0, 0, -n), true
+		case "w":
+			return now.AddDate(0, 0, -7*n), true
+		}
+		return now.AddDate(-n, 0, 0), true
+	}
+	if d, err := time.ParseDuration(input); err == nil && d >= 0 {
+		return now.Add(-d), true
+	}
+	return time.Time{}, false
+}
+
+// HasAllTags checks if a note contains all the specified tags.
+// It returns true if the note has all the tags in the provided list, or if the list is empty.
+// Returns false if any tag is missing from the note. Tags are hierarchical, so a tag such as
+// proj/atlas is also found on a note tagged with a descendant like proj/atlas/infra.
+func HasAllTags(note *Note, tags []string) bool {
 	for _, ft := range tags {
-		if !tagSet[ft] {
+		found := false
+		for _, t := range note.Tags {
+			if TagMatches(t, ft) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// TagMatches reports whether tag is the tag filter or one of its descendants,
+// whose names continue the filter's after a slash.
+func TagMatches(tag, filter string) bool {
+	filter = strings.TrimSuffix(filter, "/")
+	return tag == filter || strings.HasPrefix(tag, filter+"/")
+}
+
+// HasAllFields checks if a note's frontmatter matches all the specified field filters, whether
+// the fields are managed by jot, such as context, or extra ones. Each filter is either
+// "key=value", matching fields whose value equals value, or a bare "key", matching notes
+// where the field is present. See Note.MatchesField.
+// It returns true if the list is empty.
+func HasAllFields(note *Note, fields []string) bool {
+	for _, f := range fields {
+		key, value, _ := strings.Cut(f, "=")
+		if !note.MatchesField(strings.TrimSpace(key), strings.TrimSpace(value)) {
 			return false
 		}
 	}
@@ -0,0 +1,31 @@
+package jot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2026-01-02", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"1y", now.AddDate(-1, 0, 0)},
+		{"36h", now.Add(-36 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.input, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	for _, input := range []string{"", "soon", "-1h", "2026-13-01", "7x"} {
+		if _, err := ParseTime(input, now); err == nil {
+			t.Errorf("ParseTime(%q) succeeded, want an error", input)
+		}
+	}
+}
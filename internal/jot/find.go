@@ -2,57 +2,224 @@ package jot
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
-// FindNoteByID locates and loads a note by its ID or ID prefix.
-// It searches the notes directory for a file with a name starting with the given ID.
-// Returns the parsed Note if found, or an error if the note doesn't exist or can't be parsed.
-func FindNoteByID(baseDir, id string) (*Note, error) {
-	noteDir := filepath.Join(baseDir, "notes")
+// NotFoundError is returned when no note matches a reference given on the command line.
+// It wraps ErrNotFound.
+type NotFoundError struct {
+	// Ref is the ID, ID prefix, title, slug or alias that was looked up.
+	Ref string
+	// Area is where the note was looked for, such as "notes" or "trash".
+	Area string
+}
 
-	files, err := os.ReadDir(noteDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read notes directory at path '%s': %w", noteDir, err)
+// Error implements the error interface.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no note matching '%s' found in %s", e.Ref, e.Area)
+}
+
+// Unwrap returns ErrNotFound.
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// AmbiguousError is returned when a reference given on the command line matches more than one note.
+type AmbiguousError struct {
+	// Ref is the ID, ID prefix, title, slug or alias that was looked up.
+	Ref string
+	// Candidates are the matching notes, sorted by ID.
+	Candidates []*Note
+}
+
+// Error implements the error interface.
+func (e *AmbiguousError) Error() string {
+	ids := make([]string, 0, len(e.Candidates))
+	for _, n := range e.Candidates {
+		ids = append(ids, n.ID)
 	}
+	return fmt.Sprintf("'%s' is ambiguous: it matches %d notes (%s)", e.Ref, len(e.Candidates), strings.Join(ids, ", "))
+}
 
-	var match string
-	for _, f := range files {
-		if strings.HasPrefix(f.Name(), id) && strings.HasSuffix(f.Name(), ".md") {
-			match = filepath.Join(noteDir, f.Name())
-			break
+// ResolveNote finds the note a reference given on the command line points at, and its store key.
+// A reference is tried, in order, as the name of a note's file, as an exact ID, as a note's title,
+// slug or alias (ignoring case and punctuation), and as an ID prefix. Notes in subdirectories of
+// the notes area are included. Returns a *NotFoundError if nothing matches and an *AmbiguousError
+// if the first kind of match that applies matches more than one note.
+func ResolveNote(s Store, ref string) (*Note, string, error) {
+	// Most references are full IDs of notes in files named after their ID. A file name that no other
+	// file shares wins over every other match, so that note can be read without loading the rest.
+	if key, err := uniqueKeyName(s, notesArea, ref); err == nil && key != "" {
+		if n, err := readNote(s, key); err == nil {
+			return n, key, nil
 		}
 	}
 
-	if match == "" {
-		return nil, fmt.Errorf("note with ID or ID prefix '%s' not found in directory '%s'", id, noteDir)
+	entries, err := loadNoteEntries(s)
+	if err != nil {
+		return nil, "", err
+	}
+	e, err := resolveIn(entries, ref, "notes")
+	if err != nil {
+		return nil, "", err
 	}
+	return e.note, e.key, nil
+}
+
+// FindNoteByID locates and loads a note by its ID, ID prefix, title, slug or alias.
+// See ResolveNote for how references are matched.
+// Returns the parsed Note if found, or an error if the note doesn't exist or the reference is ambiguous.
+func FindNoteByID(s Store, id string) (*Note, error) {
+	n, _, err := ResolveNote(s, id)
+	return n, err
+}
+
+// ResolveNoteKey finds the store key of a note by its ID, ID prefix, title, slug or alias.
+// Unlike FindNoteByID, this function only returns the key of the note, not the parsed note.
+// Returns the key if found, or an error if the note doesn't exist or the reference is ambiguous.
+func ResolveNoteKey(s Store, id string) (string, error) {
+	_, key, err := ResolveNote(s, id)
+	return key, err
+}
+
+// JournalField is the frontmatter field that marks a note as the daily journal for a date.
+const JournalField = "journal"
 
-	note, err := ParseNoteFile(match)
+// FindJournal finds the daily journal note for the given day and its store key.
+// Journals are marked with a journal field holding their date; journals created before
+// the field existed are found by their old today-YYYYMMDD ID.
+// Returns a *NotFoundError if there is no journal for the day yet.
+func FindJournal(s Store, day time.Time) (*Note, string, error) {
+	date := day.Format("2006-01-02")
+	legacyID := "today-" + day.Format("20060102")
+
+	entries, err := loadNoteEntries(s)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse note with ID prefix '%s' at path '%s': %w", id, match, err)
+		return nil, "", err
+	}
+	for _, e := range entries {
+		if v, ok := e.note.Extra.GetString(JournalField); (ok && v == date) || e.note.ID == legacyID {
+			return e.note, e.key, nil
+		}
 	}
-	return note, nil
+	return nil, "", &NotFoundError{Ref: date, Area: "notes"}
 }
 
-// ResolveNotePath finds the full file path of a note by its ID or ID prefix.
-// Unlike FindNoteByID, this function only returns the path to the note file, not the parsed note.
-// Returns the full path if found, or an error if the note doesn't exist.
-func ResolveNotePath(baseDir, id string) (string, error) {
-	noteDir := filepath.Join(baseDir, "notes")
+// NoteKey returns the store key a note with the given ID is saved under.
+func NoteKey(id string) string {
+	return notesArea + id
+}
+
+// resolveKey finds the key of the note in an area of the store that a reference points at.
+func resolveKey(s Store, area, ref string) (string, error) {
+	if area == notesArea {
+		return ResolveNoteKey(s, ref)
+	}
+	entries, err := loadNotesIn(s, area)
+	if err != nil {
+		return "", err
+	}
+	e, err := resolveIn(entries, ref, strings.TrimSuffix(area, "/"))
+	return e.key, err
+}
 
-	files, err := os.ReadDir(noteDir)
+// uniqueKeyName returns the only key in an area whose name is name, or "" if there is none or more than one.
+func uniqueKeyName(s Store, area, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	keys, err := s.List(area)
 	if err != nil {
-		return "", fmt.Errorf("failed to read notes directory at path '%s': %w", noteDir, err)
+		return "", err
+	}
+	found := ""
+	for _, key := range keys {
+		if keyName(key) == name {
+			if found != "" {
+				return "", nil
+			}
+			found = key
+		}
+	}
+	return found, nil
+}
+
+// resolveIn picks the note a reference points at from a set of loaded notes.
+// Exact key names win over exact IDs, which win over titles, slugs and aliases, which win over ID prefixes.
+func resolveIn(entries []noteEntry, ref, area string) (noteEntry, error) {
+	return newResolver(entries).resolve(ref, area)
+}
+
+// resolver matches references against a set of loaded notes, as ResolveNote does. The slugs of
+// every title and alias are worked out once, so that many references can be resolved cheaply.
+type resolver struct {
+	entries []noteEntry
+	// names holds the slugs of the title and aliases of each entry.
+	names [][]string
+}
+
+// newResolver prepares a resolver over the entries.
+func newResolver(entries []noteEntry) *resolver {
+	r := &resolver{entries: entries, names: make([][]string, len(entries))}
+	for i, e := range entries {
+		names := []string{Slugify(e.note.DisplayTitle())}
+		for _, alias := range e.note.Aliases() {
+			names = append(names, Slugify(alias))
+		}
+		r.names[i] = names
+	}
+	return r
+}
+
+// resolve picks the note a reference points at. Exact key names win over exact IDs, which win
+// over titles, slugs and aliases, which win over ID prefixes.
+func (r *resolver) resolve(ref, area string) (noteEntry, error) {
+	if ref == "" {
+		return noteEntry{}, &NotFoundError{Ref: ref, Area: area}
+	}
+
+	slug := Slugify(ref)
+	var byKey, byID, byName, byPrefix []noteEntry
+	for i, e := range r.entries {
+		name := keyName(e.key)
+		switch {
+		case name == ref:
+			byKey = append(byKey, e)
+		case e.note.ID == ref:
+			byID = append(byID, e)
+		case slug != "" && slices.Contains(r.names[i], slug):
+			byName = append(byName, e)
+		case strings.HasPrefix(e.note.ID, ref) || strings.HasPrefix(name, ref):
+			byPrefix = append(byPrefix, e)
+		}
 	}
 
-	for _, f := range files {
-		if strings.HasPrefix(f.Name(), id) && strings.HasSuffix(f.Name(), ".md") {
-			return filepath.Join(noteDir, f.Name()), nil
+	for _, matches := range [][]noteEntry{byKey, byID, byName, byPrefix} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		}
+		candidates := make([]*Note, 0, len(matches))
+		for _, e := range matches {
+			candidates = append(candidates, e.note)
 		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+		return noteEntry{}, &AmbiguousError{Ref: ref, Candidates: candidates}
 	}
+	return noteEntry{}, &NotFoundError{Ref: ref, Area: area}
+}
 
-	return "", fmt.Errorf("note with ID or ID prefix '%s' not found in directory '%s'", id, noteDir)
+// resolveID returns the ID of the note a link target refers to, or an empty string if no note
+// matches or the target is ambiguous.
+func (r *resolver) resolveID(target string) string {
+	e, err := r.resolve(target, "notes")
+	if err != nil {
+		return ""
+	}
+	return e.note.ID
 }
@@ -0,0 +1,206 @@
+package jot
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// entry returns a loaded note stored under key.
+func entry(key, id, title string, aliases ...string) noteEntry {
+	n := &Note{ID: id, Title: title}
+	if len(aliases) > 0 {
+		if err := n.Extra.Set("aliases", aliases[0]); err != nil {
+			panic(err)
+		}
+	}
+	return noteEntry{key: key, note: n}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []noteEntry
+		ref     string
+		wantKey string
+	}{
+		{
+			name:    "key name wins over ID",
+			entries: []noteEntry{entry("notes/shared", "x1", ""), entry("notes/x2", "shared", "")},
+			ref:     "shared",
+			wantKey: "notes/shared",
+		},
+		{
+			name:    "ID wins over title",
+			entries: []noteEntry{entry("notes/a1-x", "review", ""), entry("notes/b2", "b2", "Review")},
+			ref:     "review",
+			wantKey: "notes/a1-x",
+		},
+		{
+			name:    "title wins over ID prefix",
+			entries: []noteEntry{entry("notes/abc12345", "abc12345", ""), entry("notes/ffff0000", "ffff0000", "ABC")},
+			ref:     "abc",
+			wantKey: "notes/ffff0000",
+		},
+		{
+			name:    "title ignores case and punctuation",
+			entries: []noteEntry{entry("notes/1", "1", "Weekly review: Q3"), entry("notes/2", "2", "Weekly")},
+			ref:     "weekly review q3",
+			wantKey: "notes/1",
+		},
+		{
+			name:    "alias",
+			entries: []noteEntry{entry("notes/1", "1", "Weekly review", "wr"), entry("notes/2", "2", "Other")},
+			ref:     "WR",
+			wantKey: "notes/1",
+		},
+		{
+			name:    "ID prefix",
+			entries: []noteEntry{entry("notes/6be4ed32", "6be4ed32", ""), entry("notes/7a000000", "7a000000", "")},
+			ref:     "6be",
+			wantKey: "notes/6be4ed32",
+		},
+		{
+			name:    "prefix of a file name with a slug",
+			entries: []noteEntry{entry("notes/6be4ed32-weekly-review", "6be4ed32", "Weekly review")},
+			ref:     "6be4ed32-weekly",
+			wantKey: "notes/6be4ed32-weekly-review",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := resolveIn(tt.entries, tt.ref, "notes")
+			if err != nil {
+				t.Fatalf("resolveIn(%q): %v", tt.ref, err)
+			}
+			if e.key != tt.wantKey {
+				t.Errorf("resolveIn(%q) = %s, want %s", tt.ref, e.key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []noteEntry
+		ref     string
+		want    []string
+	}{
+		{
+			name:    "ID prefix",
+			entries: []noteEntry{entry("notes/ab22", "ab22", ""), entry("notes/ab11", "ab11", ""), entry("notes/cd", "cd", "")},
+			ref:     "ab",
+			want:    []string{"ab11", "ab22"},
+		},
+		{
+			name:    "title",
+			entries: []noteEntry{entry("notes/2", "2", "Same"), entry("notes/1", "1", "same")},
+			ref:     "same",
+			want:    []string{"1", "2"},
+		},
+		{
+			name:    "title and alias",
+			entries: []noteEntry{entry("notes/1", "1", "Go"), entry("notes/2", "2", "Golang", "go")},
+			ref:     "go",
+			want:    []string{"1", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveIn(tt.entries, tt.ref, "notes")
+			var ambiguous *AmbiguousError
+			if !errors.As(err, &ambiguous) {
+				t.Fatalf("resolveIn(%q) error = %v, want an *AmbiguousError", tt.ref, err)
+			}
+			var ids []string
+			for _, n := range ambiguous.Candidates {
+				ids = append(ids, n.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("candidates = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveNotFound(t *testing.T) {
+	entries := []noteEntry{entry("notes/ab11", "ab11", "Something")}
+	for _, ref := range []string{"", "zz", "something else"} {
+		_, err := resolveIn(entries, ref, "trash")
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) || !errors.Is(err, ErrNotFound) || notFound.Area != "trash" {
+			t.Errorf("resolveIn(%q) error = %v, want a *NotFoundError in trash", ref, err)
+		}
+	}
+}
+
+func TestResolveNoteInMemory(t *testing.T) {
+	cfg := &Config{StoragePath: t.TempDir()}
+	cfg.UseStore(NewMemoryStore())
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	for _, n := range []*Note{
+		{ID: "6be4ed32", Title: "Weekly review", CreatedAt: now, UpdatedAt: now},
+		{ID: "6bf00000", Title: "Planning", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := SaveNote(cfg, n); err != nil {
+			t.Fatalf("SaveNote(%s): %v", n.ID, err)
+		}
+	}
+
+	for ref, want := range map[string]string{"6be4ed32": "6be4ed32", "weekly-review": "6be4ed32", "6bf": "6bf00000"} {
+		n, key, err := ResolveNote(cfg.Store(), ref)
+		if err != nil || n.ID != want || key != NoteKey(want) {
+			t.Errorf("ResolveNote(%q) = %v, %q, %v; want %s", ref, n, key, err, want)
+		}
+	}
+	if _, _, err := ResolveNote(cfg.Store(), "6b"); !errors.As(err, new(*AmbiguousError)) {
+		t.Errorf("ResolveNote(6b) error = %v, want an *AmbiguousError", err)
+	}
+}
+
+func TestResolveNoteKeyNameWins(t *testing.T) {
+	s := NewMemoryStore()
+	for key, id := range map[string]string{
+		"notes/shared":      "x1",
+		"notes/shared-copy": "shared",
+		"notes/a/dup":       "d1",
+		"notes/b/dup":       "d2",
+	} {
+		if err := s.Put(key, []byte("---\nid: "+id+"\n---\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reading the file named after the reference agrees with resolving against every note.
+	if _, key, err := ResolveNote(s, "shared"); err != nil || key != "notes/shared" {
+		t.Errorf("ResolveNote(shared) = %q, %v; want notes/shared", key, err)
+	}
+	if _, _, err := ResolveNote(s, "dup"); !errors.As(err, new(*AmbiguousError)) {
+		t.Errorf("ResolveNote(dup) error = %v, want an *AmbiguousError", err)
+	}
+}
+
+func TestSaveNoteFindsMovedNote(t *testing.T) {
+	cfg := &Config{StoragePath: t.TempDir()}
+	cfg.UseStore(NewMemoryStore())
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	n := &Note{ID: "abc", Title: "Plan", Context: "work", CreatedAt: now, UpdatedAt: now}
+	if err := cfg.Store().Put("notes/hand-named", []byte("---\nid: abc\n---\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Layout = LayoutContext
+	if err := SaveNote(cfg, n); err != nil {
+		t.Fatal(err)
+	}
+	cfg.FilenameFormat = FilenameIDSlug
+	if err := SaveNote(cfg, n); err != nil {
+		t.Fatal(err)
+	}
+	keys, _ := cfg.Store().List(notesArea)
+	if !slices.Equal(keys, []string{"notes/work/abc-plan"}) {
+		t.Errorf("keys after saving = %v, want the note moved to notes/work/abc-plan", keys)
+	}
+}
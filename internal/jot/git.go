@@ -0,0 +1,155 @@
+package jot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitConfig holds the settings for the optional git integration.
+type GitConfig struct {
+	// AutoCommit commits every file touched by a mutating command to a git repository in the storage path.
+	AutoCommit bool `yaml:"auto_commit"`
+}
+
+// gitIgnore lists the files in the storage path that should never be committed.
+const gitIgnore = "index.json\nlocks/\n"
+
+// CommitNote stages and commits the files belonging to the note with the given ID,
+// along with the documents with the given extra keys, if git auto-commit is enabled.
+// The commit message names the operation and the note ID. It is a no-op if nothing changed.
+func CommitNote(cfg *Config, op, id string, extra ...string) error {
+	if !cfg.Git.AutoCommit {
+		return nil
+	}
+
+	keys := append([]string{revisionKey(id)}, extra...)
+	if key, err := ResolveNoteKey(cfg.Store(), id); err == nil {
+		keys = append(keys, key)
+	}
+
+	return CommitKeys(cfg, fmt.Sprintf("jot: %s note %s", op, id), keys...)
+}
+
+// CommitKeys commits the files holding the documents with the given keys, if git auto-commit is enabled.
+// A key may also name a directory of documents, such as "trash".
+// Stores that do not keep their documents in files under the storage path are never committed.
+func CommitKeys(cfg *Config, message string, keys ...string) error {
+	if !cfg.Git.AutoCommit {
+		return nil
+	}
+	fb, ok := cfg.Store().(fileBacked)
+	if !ok {
+		return nil
+	}
+	return GitCommit(cfg, message, fb.Files(keys...)...)
+}
+
+// GitCommit stages the given paths and commits them with message, if git auto-commit is enabled.
+// The storage path is initialised as a git repository on first use.
+// Paths that no longer exist are staged as deletions. It is a no-op if nothing changed.
+func GitCommit(cfg *Config, message string, paths ...string) error {
+	if !cfg.Git.AutoCommit {
+		return nil
+	}
+	// Commits from concurrent jot processes would otherwise race for git's index lock.
+	lock, err := waitLock(cfg.StoragePath, "git.lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := ensureGitRepo(cfg.StoragePath); err != nil {
+		return err
+	}
+
+	var pathspecs []string
+	for _, p := range paths {
+		rel, err := filepath.Rel(cfg.StoragePath, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if _, err := os.Stat(p); err != nil {
+			if _, err := runGit(cfg.StoragePath, "ls-files", "--error-unmatch", "--", rel); err != nil {
+				continue
+			}
+		}
+		pathspecs = append(pathspecs, rel)
+	}
+	if len(pathspecs) == 0 {
+		return nil
+	}
+
+	if _, err := runGit(cfg.StoragePath, append([]string{"add", "-A", "--"}, pathspecs...)...); err != nil {
+		return err
+	}
+	if _, err := runGit(cfg.StoragePath, append([]string{"diff", "--cached", "--quiet", "--"}, pathspecs...)...); err == nil {
+		return nil
+	}
+
+	args := []string{"commit", "--quiet", "-m", message, "--"}
+	if name, _ := runGit(cfg.StoragePath, "config", "user.email"); strings.TrimSpace(name) == "" {
+		args = append([]string{"-c", "user.name=jot", "-c", "user.email=jot@localhost"}, args...)
+	}
+	_, err = runGit(cfg.StoragePath, append(args, pathspecs...)...)
+	return err
+}
+
+// GitLog returns the one-line log of commits touching the note with the given ID,
+// wherever its file lives in the storage path (notes, archive, trash or history).
+// Files are matched by the ID followed by the end of the name or a dash, so that the log of
+// note abc does not take in note abcd.
+func GitLog(baseDir, id string) (string, error) {
+	if _, err := runGit(baseDir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return "", fmt.Errorf("storage path '%s' is not a git repository", baseDir)
+	}
+	return runGit(baseDir, "log",
+		"--format=%h  %ad  %s",
+		"--date=format:%Y-%m-%d %H:%M",
+		"--",
+		":(glob)**/"+id+".md",
+		":(glob)**/"+id+"-*.md",
+		// A note trashed over another copy gets a timestamp before its extension.
+		":(glob)trash/**/"+id+".*.md",
+		":(glob)trash/**/"+id+"-*.*.md",
+		":(glob)history/"+id+"/**",
+	)
+}
+
+// ensureGitRepo initialises a git repository in dir if it is not already inside one.
+func ensureGitRepo(dir string) error {
+	if _, err := runGit(dir, "rev-parse", "--is-inside-work-tree"); err == nil {
+		return nil
+	}
+	if _, err := runGit(dir, "init", "--quiet"); err != nil {
+		return err
+	}
+
+	ignorePath := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignorePath); os.IsNotExist(err) {
+		if err := WriteFileAtomic(ignorePath, []byte(gitIgnore), 0644); err != nil {
+			return fmt.Errorf("failed to write git ignore file at path '%s': %w", ignorePath, err)
+		}
+	}
+	return nil
+}
+
+// runGit runs a git command in dir and returns its standard output.
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return stdout.String(), fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+		return stdout.String(), fmt.Errorf("git %s failed: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,210 @@
+package jot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// revisionTimeFormat is the file name format of a revision, sortable as a string.
+const revisionTimeFormat = "20060102T150405.000000000Z"
+
+// DefaultHistoryLimit is how many revisions of each note are kept unless the history_limit
+// setting says otherwise.
+const DefaultHistoryLimit = 100
+
+// updatedAtLine matches the updated_at frontmatter line, which is ignored when comparing revisions.
+var updatedAtLine = regexp.MustCompile(`(?m)^updated_at:.*$`)
+
+// Revision is a saved version of a note.
+type Revision struct {
+	// Number is the 1-based position of the revision, oldest first.
+	Number int `json:"number"`
+	// Name is the revision identifier, a UTC timestamp.
+	Name string `json:"name"`
+	// Time is when the revision was recorded.
+	Time time.Time `json:"time"`
+	// Key is the store key of the revision.
+	Key string `json:"key"`
+}
+
+// RecordRevision stores markdown as a new revision of the note with the given ID,
+// unless it matches the latest revision apart from the updated_at timestamp.
+// Returns true if a revision was written.
+func RecordRevision(s Store, id, markdown string) (bool, error) {
+	if !ValidID(id) {
+		return false, fmt.Errorf("invalid note ID '%s'", id)
+	}
+	revs, err := ListRevisions(s, id)
+	if err != nil {
+		return false, err
+	}
+	if len(revs) > 0 {
+		latest, err := s.Get(revs[len(revs)-1].Key)
+		if err == nil && sameRevision(string(latest), markdown) {
+			return false, nil
+		}
+	}
+
+	key := revisionKey(id) + "/" + time.Now().UTC().Format(revisionTimeFormat)
+	if err := s.Put(key, []byte(markdown)); err != nil {
+		return false, fmt.Errorf("failed to write revision for note ID '%s': %w", id, err)
+	}
+	return true, nil
+}
+
+// PruneRevisions deletes the oldest revisions of the note with the given ID, keeping the newest
+// keep of them. A keep of zero or less keeps every revision.
+// Returns the number of revisions deleted.
+func PruneRevisions(s Store, id string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	revs, err := ListRevisions(s, id)
+	if err != nil || len(revs) <= keep {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, rev := range revs[:len(revs)-keep] {
+		if err := s.Delete(rev.Key); err != nil {
+			return pruned, fmt.Errorf("failed to delete revision '%s': %w", rev.Key, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// SnapshotNote records the currently stored version of the note with the given key as a revision,
+// so changes made outside SaveNote (for example in an editor) can be rolled back.
+func SnapshotNote(s Store, key string) error {
+	data, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+	n, err := ParseNote(data, key)
+	if err != nil {
+		return err
+	}
+	_, err = RecordRevision(s, n.ID, string(data))
+	return err
+}
+
+// ReadRevision returns the markdown stored in a revision.
+func ReadRevision(s Store, rev Revision) (string, error) {
+	data, err := s.Get(rev.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read revision '%s': %w", rev.Key, err)
+	}
+	return string(data), nil
+}
+
+// ListRevisions returns the revisions of the note with the given ID, oldest first.
+func ListRevisions(s Store, id string) ([]Revision, error) {
+	prefix := revisionKey(id) + "/"
+	keys, err := s.List(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions of note ID '%s': %w", id, err)
+	}
+
+	var revs []Revision
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		t, err := time.Parse(revisionTimeFormat, name)
+		if err != nil {
+			continue
+		}
+		revs = append(revs, Revision{Name: name, Time: t, Key: key})
+	}
+
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Name < revs[j].Name })
+	for i := range revs {
+		revs[i].Number = i + 1
+	}
+	return revs, nil
+}
+
+// FindRevision looks up a revision of a note by its number, written as #N or a plain N, or by a
+// prefix of its name. A plain number is only taken as a revision number if the note has that many
+// revisions; otherwise it is matched against the names, which start with the revision's date.
+func FindRevision(s Store, id, rev string) (Revision, error) {
+	revs, err := ListRevisions(s, id)
+	if err != nil {
+		return Revision{}, err
+	}
+	if len(revs) == 0 {
+		return Revision{}, fmt.Errorf("note ID '%s' has no revisions", id)
+	}
+
+	if n, ok := strings.CutPrefix(rev, "#"); ok {
+		num, err := strconv.Atoi(n)
+		if err != nil || num < 1 || num > len(revs) {
+			return Revision{}, fmt.Errorf("revision '%s' not found for note ID '%s': it has revisions #1 to #%d", rev, id, len(revs))
+		}
+		return revs[num-1], nil
+	}
+	if num, err := strconv.Atoi(rev); err == nil && num >= 1 && num <= len(revs) {
+		return revs[num-1], nil
+	}
+
+	var matches []Revision
+	for _, r := range revs {
+		if strings.HasPrefix(r.Name, rev) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Revision{}, fmt.Errorf("revision '%s' not found for note ID '%s'", rev, id)
+	case 1:
+		return matches[0], nil
+	default:
+		return Revision{}, fmt.Errorf("revision '%s' is ambiguous for note ID '%s' (%d matches)", rev, id, len(matches))
+	}
+}
+
+// ResolveHistoryID returns the full ID of a note with history, given a reference to it as accepted by ResolveNote.
+// Notes that have been deleted are still found by ID or ID prefix as long as their history exists.
+func ResolveHistoryID(s Store, id string) (string, error) {
+	n, err := FindNoteByID(s, id)
+	if err == nil {
+		return n.ID, nil
+	}
+	var ambiguous *AmbiguousError
+	if errors.As(err, &ambiguous) {
+		return "", err
+	}
+
+	keys, err := s.List(historyArea)
+	if err != nil {
+		return "", err
+	}
+	var matches []*Note
+	for _, key := range keys {
+		noteID, _, _ := strings.Cut(strings.TrimPrefix(key, historyArea), "/")
+		if strings.HasPrefix(noteID, id) && (len(matches) == 0 || matches[len(matches)-1].ID != noteID) {
+			matches = append(matches, &Note{ID: noteID})
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", &NotFoundError{Ref: id, Area: "notes or history"}
+	case 1:
+		return matches[0].ID, nil
+	}
+	for _, m := range matches {
+		if m.ID == id {
+			return id, nil
+		}
+	}
+	return "", &AmbiguousError{Ref: id, Candidates: matches}
+}
+
+// sameRevision reports whether two markdown documents are equal, ignoring the updated_at timestamp.
+func sameRevision(a, b string) bool {
+	return updatedAtLine.ReplaceAllString(a, "") == updatedAtLine.ReplaceAllString(b, "")
+}
@@ -0,0 +1,53 @@
+package jot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindRevision(t *testing.T) {
+	s := NewMemoryStore()
+	names := []string{
+		"20260315T120000.000000000Z",
+		"20260315T130000.000000000Z",
+		"20260316T090000.000000000Z",
+	}
+	for _, name := range names {
+		if err := s.Put(revisionKey("abc")+"/"+name, []byte("---\nid: abc\n---\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		rev  string
+		want string
+		err  string
+	}{
+		{rev: "1", want: names[0]},
+		{rev: "#3", want: names[2]},
+		{rev: "3", want: names[2]},
+		{rev: "20260316", want: names[2]},
+		{rev: "20260315T13", want: names[1]},
+		{rev: "2026", err: "ambiguous"},
+		{rev: "4", err: "not found"},
+		{rev: "#4", err: "#1 to #3"},
+		{rev: "#0", err: "#1 to #3"},
+		{rev: "#x", err: "#1 to #3"},
+		{rev: "2027", err: "not found"},
+	}
+	for _, tt := range tests {
+		r, err := FindRevision(s, "abc", tt.rev)
+		switch {
+		case tt.err != "":
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("FindRevision(%q) error = %v, want one mentioning %q", tt.rev, err, tt.err)
+			}
+		case err != nil || r.Name != tt.want:
+			t.Errorf("FindRevision(%q) = %s, %v; want %s", tt.rev, r.Name, err, tt.want)
+		}
+	}
+
+	if _, err := FindRevision(s, "none", "1"); err == nil {
+		t.Error("FindRevision for a note without revisions succeeded")
+	}
+}
@@ -0,0 +1,191 @@
+package jot
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ID schemes that can be selected with the id_scheme config setting.
+const (
+	// IDHex is a random 8-character hexadecimal ID, the default.
+	IDHex = "hex"
+	// IDULID is a 26-character ULID, which sorts in creation order.
+	IDULID = "ulid"
+	// IDZettel is a Zettelkasten timestamp of the form YYYYMMDDHHMM.
+	IDZettel = "zettel"
+	// IDSlug is the slug of the note's title.
+	IDSlug = "slug"
+)
+
+// maxIDAttempts is how many IDs are tried before giving up on finding an unused one.
+const maxIDAttempts = 100
+
+// ErrNoteExists is returned when creating a note whose ID is already used by another note.
+var ErrNoteExists = errors.New("a note with this ID already exists")
+
+// crockford is the alphabet ULIDs are encoded in.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ValidIDScheme reports whether scheme names a known ID scheme. The empty scheme means IDHex.
+func ValidIDScheme(scheme string) bool {
+	switch scheme {
+	case "", IDHex, IDULID, IDZettel, IDSlug:
+		return true
+	}
+	return false
+}
+
+// ValidID reports whether id can be used as a note ID. IDs name files, revision directories
+// and lock files and appear in git pathspecs, so they must be a single path element that is
+// not hidden and has no whitespace, control characters, backslashes or glob characters.
+func ValidID(id string) bool {
+	if id == "" || id == "." || id == ".." || strings.HasPrefix(id, ".") {
+		return false
+	}
+	return !strings.ContainsFunc(id, func(r rune) bool {
+		return r == '/' || r == '\\' || r == '*' || r == '?' || r == '[' || r == ']' || unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+}
+
+// NewNoteID returns an ID for a new note that no note in the store uses yet.
+// The configured ID scheme decides its form; title is only used by IDSlug, which falls
+// back to IDZettel for an untitled note. Random IDs are regenerated on a collision,
+// while timestamp and slug IDs get a -2, -3, ... suffix.
+func NewNoteID(cfg *Config, title string, now time.Time) (string, error) {
+	s := cfg.Store()
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		var id string
+		switch cfg.IDScheme {
+		case "", IDHex:
+			id = randomHex(4)
+		case IDULID:
+			id = newULID(now)
+		case IDZettel, IDSlug:
+			id = Slugify(title)
+			if cfg.IDScheme == IDZettel || id == "" {
+				id = now.Format("200601021504")
+			}
+			if attempt > 0 {
+				id += "-" + strconv.Itoa(attempt+1)
+			}
+		default:
+			return "", fmt.Errorf("unknown ID scheme '%s': use '%s', '%s', '%s' or '%s'", cfg.IDScheme, IDHex, IDULID, IDZettel, IDSlug)
+		}
+
+		used, err := IDInUse(s, id)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("failed to find an unused note ID after %d attempts", maxIDAttempts)
+}
+
+// NewJournalID returns an ID for a new daily note that no note in the store uses yet. Unless an
+// ID scheme is configured, it is today-YYYYMMDD, as daily notes have always been named, with a
+// -2, -3, ... suffix on a collision. A configured scheme is used as by NewNoteID.
+func NewJournalID(cfg *Config, title string, now time.Time) (string, error) {
+	if cfg.IDScheme != "" {
+		return NewNoteID(cfg, title, now)
+	}
+	base := "today-" + now.Format("20060102")
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		id := base
+		if attempt > 0 {
+			id += "-" + strconv.Itoa(attempt+1)
+		}
+		used, err := IDInUse(cfg.Store(), id)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("failed to find an unused note ID after %d attempts", maxIDAttempts)
+}
+
+// IDInUse reports whether a note, archived note or trashed note is stored under id,
+// so that creating a note with it could overwrite or later collide with that note.
+// A file named after id counts whatever it holds; a file whose name merely starts
+// with id, such as one with a title slug, counts if the note in it has that ID.
+func IDInUse(s Store, id string) (bool, error) {
+	for _, area := range []string{notesArea, archiveArea, trashArea} {
+		used, err := idInArea(s, area, id)
+		if err != nil || used {
+			return used, err
+		}
+	}
+	return false, nil
+}
+
+// idInArea reports whether a note is stored under id in one area of the store, as IDInUse does.
+func idInArea(s Store, area, id string) (bool, error) {
+	keys, err := s.List(area)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		name := keyName(key)
+		if name == id {
+			return true, nil
+		}
+		if !strings.HasPrefix(name, id+"-") && !strings.HasPrefix(name, id+".") {
+			continue
+		}
+		if n, err := readNote(s, key); err == nil && n.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateNote saves a new note like SaveNote, but fails with ErrNoteExists instead of
+// overwriting a note that already uses its ID.
+func CreateNote(cfg *Config, note *Note) error {
+	used, err := IDInUse(cfg.Store(), note.ID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return fmt.Errorf("cannot create note '%s': %w", note.ID, ErrNoteExists)
+	}
+	return SaveNote(cfg, note)
+}
+
+// randomHex returns n random bytes encoded as hexadecimal.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newULID returns a ULID for the given time: a 48-bit millisecond timestamp followed by
+// 80 random bits, encoded as 26 characters of Crockford base32.
+func newULID(t time.Time) string {
+	var b [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(b[:6], ms[2:])
+	_, _ = rand.Read(b[6:])
+
+	// 128 bits are encoded 5 at a time, most significant first, after two leading zero bits.
+	out := make([]byte, 26)
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
@@ -0,0 +1,118 @@
+package jot
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryConfig returns a config whose vault is an empty MemoryStore.
+func memoryConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg := &Config{Editor: "vi", StoragePath: t.TempDir()}
+	cfg.UseStore(NewMemoryStore())
+	return cfg
+}
+
+func TestNewNoteID(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		scheme string
+		title  string
+		want   *regexp.Regexp
+	}{
+		{"", "Anything", regexp.MustCompile(`^[0-9a-f]{8}$`)},
+		{IDHex, "Anything", regexp.MustCompile(`^[0-9a-f]{8}$`)},
+		{IDULID, "Anything", regexp.MustCompile(`^01[0-9A-HJKMNP-TV-Z]{24}$`)},
+		{IDZettel, "Anything", regexp.MustCompile(`^202603151230$`)},
+		{IDSlug, "Weekly review: Q3", regexp.MustCompile(`^weekly-review-q3$`)},
+		{IDSlug, "", regexp.MustCompile(`^202603151230$`)},
+	}
+	for _, tt := range tests {
+		cfg := memoryConfig(t)
+		cfg.IDScheme = tt.scheme
+		id, err := NewNoteID(cfg, tt.title, now)
+		if err != nil {
+			t.Fatalf("NewNoteID(%s, %q): %v", tt.scheme, tt.title, err)
+		}
+		if !tt.want.MatchString(id) || !ValidID(id) {
+			t.Errorf("NewNoteID(%s, %q) = %q, want a match for %s", tt.scheme, tt.title, id, tt.want)
+		}
+	}
+}
+
+func TestNewNoteIDCollisions(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 30, 0, 0, time.UTC)
+	cfg := memoryConfig(t)
+	cfg.IDScheme = IDSlug
+
+	// Archived and trashed notes keep their IDs taken too.
+	for _, key := range []string{"notes/plan", "archive/plan-2", "trash/plan-3"} {
+		if err := cfg.Store().Put(key, []byte("---\nid: "+keyName(key)+"\n---\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, err := NewNoteID(cfg, "Plan", now)
+	if err != nil || id != "plan-4" {
+		t.Fatalf("NewNoteID with plan, plan-2 and plan-3 taken = %q, %v; want plan-4", id, err)
+	}
+
+	// A file whose name starts with the ID but holds another note does not take the ID.
+	if err := cfg.Store().Put("notes/idea-list", []byte("---\nid: other\n---\n")); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := NewNoteID(cfg, "Idea", now); err != nil || id != "idea" {
+		t.Fatalf("NewNoteID(Idea) = %q, %v; want idea", id, err)
+	}
+
+	cfg.IDScheme = "uuid"
+	if _, err := NewNoteID(cfg, "x", now); err == nil {
+		t.Fatal("NewNoteID with an unknown scheme succeeded")
+	}
+}
+
+func TestNewJournalID(t *testing.T) {
+	day := time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC)
+	cfg := memoryConfig(t)
+	if id, err := NewJournalID(cfg, "Journal", day); err != nil || id != "today-20260315" {
+		t.Fatalf("NewJournalID = %q, %v; want today-20260315", id, err)
+	}
+	if err := cfg.Store().Put("notes/today-20260315", []byte("---\nid: today-20260315\n---\n")); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := NewJournalID(cfg, "Journal", day); err != nil || id != "today-20260315-2" {
+		t.Fatalf("NewJournalID with the day taken = %q, %v; want today-20260315-2", id, err)
+	}
+
+	cfg.IDScheme = IDZettel
+	if id, _ := NewJournalID(cfg, "Journal", day); id != "202603150800" {
+		t.Fatalf("NewJournalID with the zettel scheme = %q, want 202603150800", id)
+	}
+}
+
+func TestCreateNoteRefusesTakenID(t *testing.T) {
+	cfg := memoryConfig(t)
+	now := time.Now()
+	if err := CreateNote(cfg, &Note{ID: "abc", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	err := CreateNote(cfg, &Note{ID: "abc", CreatedAt: now, UpdatedAt: now, Content: "other"})
+	if !errors.Is(err, ErrNoteExists) {
+		t.Fatalf("CreateNote over an existing note: got %v, want ErrNoteExists", err)
+	}
+}
+
+func TestValidID(t *testing.T) {
+	for _, id := range []string{"6be4ed32", "today-20260315", "01HV6Z3Q8K", "weekly-review-q3", "a.b"} {
+		if !ValidID(id) {
+			t.Errorf("ValidID(%q) = false, want true", id)
+		}
+	}
+	for _, id := range []string{"", ".", "..", ".hidden", "../evil", "a/b", `a\b`, "a b", "a*", "a?", "[a]", "a\tb", strings.Repeat("x", 3) + "\n"} {
+		if ValidID(id) {
+			t.Errorf("ValidID(%q) = true, want false", id)
+		}
+	}
+}
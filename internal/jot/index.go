@@ -0,0 +1,200 @@
+package jot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// indexVersion is bumped whenever the on-disk index format changes.
+// An index with a different version is discarded and rebuilt.
+const indexVersion = 3
+
+// noteIndex is a persistent cache of the parsed notes of a FileStore, keyed by store key.
+// An entry is reused as long as the file's modification time and size are unchanged.
+type noteIndex struct {
+	Version int                    `json:"version"`
+	Entries map[string]*indexEntry `json:"entries"`
+
+	dirty bool
+}
+
+// indexEntry is the cached state of a single note file.
+type indexEntry struct {
+	ModTime   time.Time `json:"mod_time"`
+	Size      int64     `json:"size"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Tags      []string  `json:"tags,omitempty"`
+	Links     []string  `json:"links,omitempty"`
+	Context   string    `json:"context,omitempty"`
+	Content   string    `json:"content"`
+	// Frontmatter is the raw YAML frontmatter, kept only when the note has extra fields,
+	// comments or a non-standard key order that cannot be rebuilt from the fields above.
+	Frontmatter string `json:"frontmatter,omitempty"`
+}
+
+// IndexPath returns the path of the note index file inside the base directory.
+func IndexPath(baseDir string) string {
+	return filepath.Join(baseDir, "index.json")
+}
+
+// loadIndex reads the note index from disk.
+// A missing, unreadable or outdated index is treated as empty so that it is rebuilt.
+func loadIndex(baseDir string) *noteIndex {
+	idx := &noteIndex{Version: indexVersion, Entries: make(map[string]*indexEntry)}
+
+	data, err := os.ReadFile(IndexPath(baseDir))
+	if err != nil {
+		return idx
+	}
+
+	var stored noteIndex
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != indexVersion || stored.Entries == nil {
+		idx.dirty = true
+		return idx
+	}
+	return &stored
+}
+
+// save writes the index to disk if it has changed since it was loaded.
+func (idx *noteIndex) save(baseDir string) error {
+	if !idx.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to encode note index: %w", err)
+	}
+
+	path := IndexPath(baseDir)
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write note index to path '%s': %w", path, err)
+	}
+	idx.dirty = false
+	return nil
+}
+
+// note returns the cached note for key if the entry is still fresh for the given file info.
+func (idx *noteIndex) note(key string, info fs.FileInfo) (*Note, bool) {
+	e, ok := idx.Entries[key]
+	if !ok || e.Size != info.Size() || !e.ModTime.Equal(info.ModTime()) {
+		return nil, false
+	}
+
+	n := &Note{
+		ID:        e.ID,
+		Title:     e.Title,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+		Tags:      e.Tags,
+		Links:     e.Links,
+		Context:   e.Context,
+		Content:   e.Content,
+	}
+	if e.Frontmatter != "" {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(e.Frontmatter), &doc); err != nil || len(doc.Content) == 0 {
+			return nil, false
+		}
+		n.frontmatter = doc.Content[0]
+		n.Extra = splitFrontmatter(n.frontmatter)
+	}
+	if n.Tags == nil {
+		n.Tags = []string{}
+	}
+	if n.Links == nil {
+		n.Links = []string{}
+	}
+	return n, true
+}
+
+// put stores a freshly parsed note in the index.
+func (idx *noteIndex) put(key string, info fs.FileInfo, n *Note) {
+	e := &indexEntry{
+		ModTime:   info.ModTime(),
+		Size:      info.Size(),
+		ID:        n.ID,
+		Title:     n.Title,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		Tags:      n.Tags,
+		Links:     n.Links,
+		Context:   n.Context,
+		Content:   n.Content,
+	}
+	if n.frontmatter != nil && !isPlainFrontmatter(n.frontmatter) {
+		if out, err := yaml.Marshal(n.frontmatter); err == nil {
+			e.Frontmatter = string(out)
+		}
+	}
+	idx.Entries[key] = e
+	idx.dirty = true
+}
+
+// prune drops entries for files that no longer exist.
+func (idx *noteIndex) prune(seen map[string]bool) {
+	for key := range idx.Entries {
+		if !seen[key] {
+			delete(idx.Entries, key)
+			idx.dirty = true
+		}
+	}
+}
+
+// isPlainFrontmatter reports whether a frontmatter mapping holds only jot-managed keys,
+// in the order ToMarkdown writes them and without comments, so it can be rebuilt from the note fields.
+func isPlainFrontmatter(mapping *yaml.Node) bool {
+	order := []string{"id", "title", "created_at", "updated_at", "tags", "links", "context"}
+	if mapping.HeadComment != "" || mapping.LineComment != "" || mapping.FootComment != "" {
+		return false
+	}
+
+	next := 0
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if key.HeadComment != "" || key.LineComment != "" || key.FootComment != "" || value.LineComment != "" {
+			return false
+		}
+		for next < len(order) && order[next] != key.Value {
+			next++
+		}
+		if next == len(order) {
+			return false
+		}
+		next++
+	}
+	return true
+}
+
+// readNote loads the note with the given key. It parses the note's file directly rather than
+// going through the note index, which holds every note and only pays off when listing them.
+func readNote(s Store, key string) (*Note, error) {
+	data, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNote(data, key)
+}
+
+// RebuildIndex discards the note index and rebuilds it from every note file.
+// Stores other than a FileStore keep no index, so their notes are only counted.
+// Returns the number of notes indexed.
+func RebuildIndex(s Store) (int, error) {
+	if fs, ok := s.(*FileStore); ok {
+		path := IndexPath(fs.root)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to remove note index at path '%s': %w", path, err)
+		}
+	}
+	notes, err := LoadAllNotes(s)
+	return len(notes), err
+}
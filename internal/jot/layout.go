@@ -0,0 +1,205 @@
+package jot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filename formats that can be selected with the filename_format config setting.
+const (
+	// FilenameID names note files after the note ID alone, e.g. 1e83df1f.md. It is the default.
+	FilenameID = "id"
+	// FilenameIDSlug adds the slug of the note's title, e.g. 1e83df1f-weekly-review.md.
+	FilenameIDSlug = "id-slug"
+)
+
+// Directory layouts that can be selected with the layout config setting.
+const (
+	// LayoutFlat keeps every note directly in notes/. It is the default.
+	LayoutFlat = "flat"
+	// LayoutContext keeps notes in a directory per context, notes/<context>/.
+	LayoutContext = "context"
+	// LayoutDate keeps notes in a directory per month of creation, notes/YYYY/MM/.
+	LayoutDate = "date"
+	// LayoutContextDate keeps notes in a directory per context and year of creation, notes/<context>/YYYY/.
+	LayoutContextDate = "context-date"
+)
+
+// maxFilenameSlug is the longest title slug put in a filename. Longer slugs are cut at a hyphen.
+const maxFilenameSlug = 60
+
+// ValidFilenameFormat reports whether format names a known filename format. The empty format means FilenameID.
+func ValidFilenameFormat(format string) bool {
+	switch format {
+	case "", FilenameID, FilenameIDSlug:
+		return true
+	}
+	return false
+}
+
+// ValidLayout reports whether layout names a known directory layout. The empty layout means LayoutFlat.
+func ValidLayout(layout string) bool {
+	switch layout {
+	case "", LayoutFlat, LayoutContext, LayoutDate, LayoutContextDate:
+		return true
+	}
+	return false
+}
+
+// NoteKeyFor returns the store key the note is saved under with the configured layout and filename format.
+// Every format starts the name with the note ID, so ID prefixes keep resolving to the note.
+func (c *Config) NoteKeyFor(n *Note) string {
+	return notesArea + noteDir(c.Layout, n) + noteFileName(c.FilenameFormat, n)
+}
+
+// noteDir returns the directory, relative to the notes area and ending in a slash, that a note
+// belongs in with the given layout. Notes without a context stay out of context directories.
+func noteDir(layout string, n *Note) string {
+	ctx := contextDir(n.Context)
+	switch layout {
+	case LayoutContext:
+		return ctx
+	case LayoutDate:
+		return n.CreatedAt.Format("2006/01/")
+	case LayoutContextDate:
+		return ctx + n.CreatedAt.Format("2006/")
+	}
+	return ""
+}
+
+// contextDir returns the directory name for a context, ending in a slash, or "" for no context.
+// Characters that would nest or escape the directory are replaced.
+func contextDir(context string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(context))
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return ""
+	}
+	return name + "/"
+}
+
+// noteFileName returns the name, without extension, of the file a note is kept in.
+func noteFileName(format string, n *Note) string {
+	if format == FilenameIDSlug {
+		if slug := truncateSlug(Slugify(n.DisplayTitle()), maxFilenameSlug); slug != "" && slug != n.ID {
+			return n.ID + "-" + slug
+		}
+	}
+	return n.ID
+}
+
+// truncateSlug shortens slug to at most max bytes, cutting at a hyphen where possible.
+func truncateSlug(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+	slug = slug[:max]
+	if i := strings.LastIndexByte(slug, '-'); i > 0 {
+		slug = slug[:i]
+	}
+	return strings.TrimRight(slug, "-")
+}
+
+// findNoteKey returns the key the note with exactly the given ID is stored under in the notes area,
+// or "" if there is no such note. The key the note is expected under is checked first, so that finding
+// a note that has not moved reads a single file. Then files named after the ID are checked, in whatever
+// directory the layout put them; otherwise every note is loaded so that notes in hand-named files are found too.
+func findNoteKey(s Store, id, expected string) (string, error) {
+	if n, err := readNote(s, expected); err == nil && n.ID == id {
+		return expected, nil
+	}
+	if _, key, err := readNamedNote(s, id); err != nil || key != "" {
+		return key, err
+	}
+
+	entries, err := loadNoteEntries(s)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if e.note.ID == id {
+			return e.key, nil
+		}
+	}
+	return "", nil
+}
+
+// readNamedNote reads the note with exactly the given ID from a file named after it, with or without
+// a title slug, anywhere in the notes area. Returns an empty key if there is no such file.
+func readNamedNote(s Store, id string) (*Note, string, error) {
+	if id == "" {
+		return nil, "", nil
+	}
+	keys, err := s.List(notesArea)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, key := range keys {
+		if name := keyName(key); name == id || strings.HasPrefix(name, id+"-") {
+			if n, err := readNote(s, key); err == nil && n.ID == id {
+				return n, key, nil
+			}
+		}
+	}
+	return nil, "", nil
+}
+
+// Rename records a note file that was renamed from one store key to another.
+type Rename struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenameNotes moves every note whose key does not match the configured layout and filename
+// format to the key it should have. With dryRun set, nothing is changed and the renames that
+// would be made are returned. It stops with an error rather than overwrite a document
+// that already uses the key a note should move to.
+func RenameNotes(cfg *Config, dryRun bool) ([]Rename, error) {
+	s := cfg.Store()
+	entries, err := loadNoteEntries(s)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		taken[e.key] = true
+	}
+
+	var renames []Rename
+	for _, e := range entries {
+		to := cfg.NoteKeyFor(e.note)
+		if to == e.key {
+			continue
+		}
+		if taken[to] {
+			return renames, fmt.Errorf("cannot rename note '%s' from '%s': '%s' already exists", e.note.ID, e.key, to)
+		}
+		if !dryRun {
+			if err := moveKey(s, e.key, to); err != nil {
+				return renames, err
+			}
+		}
+		taken[to], taken[e.key] = true, false
+		renames = append(renames, Rename{ID: e.note.ID, From: e.key, To: to})
+	}
+	return renames, nil
+}
+
+// moveKey moves a document from one key to another.
+func moveKey(s Store, from, to string) error {
+	data, err := s.Get(from)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(to, data); err != nil {
+		return err
+	}
+	return s.Delete(from)
+}
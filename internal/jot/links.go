@@ -0,0 +1,240 @@
+package jot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// inlineLinkPattern matches inline [[target]] and [[target|label]] references in note content.
+var inlineLinkPattern = regexp.MustCompile(`\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]*))?\]\]`)
+
+// WikiLink is an inline [[target]] or [[target|label]] reference found in note content.
+type WikiLink struct {
+	// Target is the note ID, ID prefix, title, slug or alias being referenced.
+	Target string
+	// Label is the optional display text after the pipe.
+	Label string
+	// Start and End are the byte offsets of the whole [[...]] reference in the content.
+	Start int
+	End   int
+}
+
+// Link is a reference from one note to another, either from the frontmatter links list
+// or inline in the note content.
+type Link struct {
+	// Source is the ID of the note containing the link.
+	Source string `json:"source"`
+	// Target is the link text as written in the note.
+	Target string `json:"target"`
+	// ID is the ID of the note the link resolves to, or empty if it does not resolve.
+	ID string `json:"id,omitempty"`
+	// Label is the display text of an inline [[target|label]] reference.
+	Label string `json:"label,omitempty"`
+	// Inline is true if the link appears in the note content rather than the frontmatter.
+	Inline bool `json:"inline"`
+}
+
+// IsExternal reports whether the link points to a resource outside the vault, such as a URL.
+func (l Link) IsExternal() bool {
+	return strings.Contains(l.Target, "://")
+}
+
+// IsBroken reports whether the link refers to a note that does not exist.
+func (l Link) IsBroken() bool {
+	return l.ID == "" && !l.IsExternal()
+}
+
+// LinkGraph holds the resolved links between a set of notes.
+type LinkGraph struct {
+	notes    map[string]*Note
+	entries  []noteEntry
+	resolver *resolver
+	outgoing map[string][]Link
+	incoming map[string][]Link
+}
+
+// ParseWikiLinks returns all inline [[target]] and [[target|label]] references in content, in order of appearance.
+func ParseWikiLinks(content string) []WikiLink {
+	var links []WikiLink
+	for _, m := range inlineLinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		target := strings.TrimSpace(content[m[2]:m[3]])
+		if target == "" {
+			continue
+		}
+		l := WikiLink{Target: target, Start: m[0], End: m[1]}
+		if m[4] >= 0 {
+			l.Label = strings.TrimSpace(content[m[4]:m[5]])
+		}
+		links = append(links, l)
+	}
+	return links
+}
+
+// InlineLinks returns the targets of all inline [[...]] references in content, in order of appearance.
+func InlineLinks(content string) []string {
+	var targets []string
+	for _, l := range ParseWikiLinks(content) {
+		targets = append(targets, l.Target)
+	}
+	return targets
+}
+
+// DeriveTitle returns the title implied by note content: its first non-blank line, without the
+// leading markers if that line is an ATX heading such as "# Weekly review". Empty headings are skipped.
+func DeriveTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if level := len(line) - len(strings.TrimLeft(line, "#")); level >= 1 && level <= 6 {
+			if rest := line[level:]; rest == "" || rest[0] == ' ' || rest[0] == '\t' {
+				if title := strings.TrimSpace(rest); title != "" {
+					return title
+				}
+				continue
+			}
+		}
+		return line
+	}
+	return ""
+}
+
+// LoadLinkGraph loads every note in the store and resolves their links. See BuildLinkGraph.
+func LoadLinkGraph(s Store) (*LinkGraph, error) {
+	entries, err := loadNoteEntries(s)
+	if err != nil {
+		return nil, err
+	}
+	return buildLinkGraph(entries), nil
+}
+
+// BuildLinkGraph resolves the frontmatter links and inline references of every note.
+// Link targets are resolved exactly as references on the command line are by ResolveNote:
+// an exact ID, then a title, slug or alias, then a unique ID prefix. Notes are taken to be in
+// files named after their ID; use LoadLinkGraph to also resolve targets naming other files.
+func BuildLinkGraph(notes []*Note) *LinkGraph {
+	entries := make([]noteEntry, len(notes))
+	for i, n := range notes {
+		entries[i] = noteEntry{key: NoteKey(n.ID), note: n}
+	}
+	return buildLinkGraph(entries)
+}
+
+// buildLinkGraph resolves the links of a set of loaded notes.
+func buildLinkGraph(entries []noteEntry) *LinkGraph {
+	g := &LinkGraph{
+		notes:    make(map[string]*Note, len(entries)),
+		entries:  entries,
+		resolver: newResolver(entries),
+		outgoing: make(map[string][]Link),
+		incoming: make(map[string][]Link),
+	}
+	for _, e := range entries {
+		g.notes[e.note.ID] = e.note
+	}
+
+	for _, e := range entries {
+		n := e.note
+		seen := make(map[string]bool)
+		linked := make(map[string]bool)
+		add := func(target, label string, inline bool) {
+			if seen[target] {
+				return
+			}
+			seen[target] = true
+
+			l := Link{Source: n.ID, Target: target, Label: label, Inline: inline}
+			if !l.IsExternal() {
+				l.ID = g.Resolve(target)
+			}
+			g.outgoing[n.ID] = append(g.outgoing[n.ID], l)
+			if l.ID != "" && l.ID != n.ID && !linked[l.ID] {
+				linked[l.ID] = true
+				g.incoming[l.ID] = append(g.incoming[l.ID], l)
+			}
+		}
+
+		for _, target := range n.Links {
+			add(target, "", false)
+		}
+		for _, l := range ParseWikiLinks(n.Content) {
+			add(l.Target, l.Label, true)
+		}
+	}
+
+	return g
+}
+
+// Resolve returns the ID of the note a link target refers to, or an empty string if
+// no note matches or the target is ambiguous. Targets are matched as ResolveNote matches references.
+func (g *LinkGraph) Resolve(target string) string {
+	return g.resolver.resolveID(target)
+}
+
+// Notes returns the notes in the graph.
+func (g *LinkGraph) Notes() []*Note {
+	return entryNotes(g.entries)
+}
+
+// Note returns the note with the given ID, or nil if it is not in the graph.
+func (g *LinkGraph) Note(id string) *Note {
+	return g.notes[id]
+}
+
+// Outgoing returns every link written in the note with the given ID.
+func (g *LinkGraph) Outgoing(id string) []Link {
+	return g.outgoing[id]
+}
+
+// Backlinks returns the links from other notes that resolve to the note with the given ID.
+func (g *LinkGraph) Backlinks(id string) []Link {
+	return g.incoming[id]
+}
+
+// Broken returns the links in the note with the given ID that do not resolve to any note.
+func (g *LinkGraph) Broken(id string) []Link {
+	var broken []Link
+	for _, l := range g.outgoing[id] {
+		if l.IsBroken() {
+			broken = append(broken, l)
+		}
+	}
+	return broken
+}
+
+// SyncInlineLinks adds the IDs of notes referenced inline in the note content to its
+// frontmatter links list. Existing links are kept; references that do not resolve are skipped.
+func SyncInlineLinks(s Store, note *Note) error {
+	wiki := ParseWikiLinks(note.Content)
+	if len(wiki) == 0 {
+		return nil
+	}
+
+	// Only this note's links are resolved, against the notes as the index has them; the links of
+	// every other note, which a full link graph would resolve too, are not needed here.
+	entries, err := loadNoteEntries(s)
+	if err != nil {
+		return fmt.Errorf("failed to load notes to resolve inline links of note ID '%s': %w", note.ID, err)
+	}
+	r := newResolver(entries)
+
+	have := make(map[string]bool)
+	for _, l := range note.Links {
+		have[l] = true
+		if id := r.resolveID(l); id != "" {
+			have[id] = true
+		}
+	}
+
+	for _, l := range wiki {
+		id := r.resolveID(l.Target)
+		if id == "" || id == note.ID || have[id] {
+			continue
+		}
+		have[id] = true
+		note.Links = append(note.Links, id)
+	}
+	return nil
+}
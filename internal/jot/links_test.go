@@ -0,0 +1,53 @@
+package jot
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeriveTitle(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"# Weekly review\n\nNotes", "Weekly review"},
+		{"\n\n  ## Plan  \ntext", "Plan"},
+		{"###### Deep", "Deep"},
+		{"#\nnext", "next"},
+		{"First line\n# Heading later", "First line"},
+		{"#hashtag at the start\n# Heading", "#hashtag at the start"},
+		{"####### Too deep", "####### Too deep"},
+		{"```go\ncode\n```", "```go"},
+		{"", ""},
+		{"\n \n", ""},
+	}
+	for _, tt := range tests {
+		if got := DeriveTitle(tt.content); got != tt.want {
+			t.Errorf("DeriveTitle(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestSaveNoteSyncsInlineLinks(t *testing.T) {
+	cfg := &Config{StoragePath: t.TempDir(), SyncLinks: true}
+	cfg.UseStore(NewMemoryStore())
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	for _, n := range []*Note{
+		{ID: "aaa", Title: "Roadmap", CreatedAt: now, UpdatedAt: now},
+		{ID: "bbb", Title: "Budget", CreatedAt: now, UpdatedAt: now},
+		{ID: "ccc", CreatedAt: now, UpdatedAt: now, Links: []string{"bbb"},
+			Content: "See [[Roadmap]], [[budget|the budget]], [[ccc]] and [[missing]]."},
+	} {
+		if err := SaveNote(cfg, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := FindNoteByID(cfg.Store(), "ccc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bbb", "aaa"}; !slices.Equal(n.Links, want) {
+		t.Errorf("links = %v, want %v", n.Links, want)
+	}
+}
@@ -2,38 +2,108 @@ package jot
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
-	"path/filepath"
-	"strings"
+	"sort"
 )
 
-// LoadAllNotes loads all notes from the notes directory.
-// It recursively walks through the directory and parses all markdown files.
-// Returns a slice of all successfully parsed notes and any error encountered during directory traversal.
+// LoadAllNotes loads all notes from the store.
+// It parses every document in the notes area, including those in subdirectories.
+// Returns a slice of all successfully parsed notes and any error encountered while listing the store.
 // Note that parsing errors for individual files are logged to stderr but don't stop the process.
-func LoadAllNotes(baseDir string) ([]*Note, error) {
-	noteDir := filepath.Join(baseDir, "notes")
-	var notes []*Note
+// For a FileStore, unchanged files are read from the note index instead of being parsed again,
+// and the index is refreshed with any files that were added, changed or removed.
+func LoadAllNotes(s Store) ([]*Note, error) {
+	entries, err := loadNoteEntries(s)
+	return entryNotes(entries), err
+}
+
+// noteEntry is a note together with the store key it was loaded from.
+type noteEntry struct {
+	key  string
+	note *Note
+}
+
+// entryNotes returns the notes of a set of entries.
+func entryNotes(entries []noteEntry) []*Note {
+	notes := make([]*Note, 0, len(entries))
+	for _, e := range entries {
+		notes = append(notes, e.note)
+	}
+	return notes
+}
+
+// loadNoteEntries loads every note in the notes area along with its key.
+func loadNoteEntries(s Store) ([]noteEntry, error) {
+	if fs, ok := s.(*FileStore); ok {
+		return loadIndexedNotes(fs)
+	}
+	return loadNotesIn(s, notesArea)
+}
+
+// loadIndexedNotes loads all notes from a FileStore, using and refreshing the note index.
+func loadIndexedNotes(s *FileStore) ([]noteEntry, error) {
+	infos, err := s.scan(notesArea)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(infos))
+	for key := range infos {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	err := filepath.WalkDir(noteDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
-			return nil
+	var notes []noteEntry
+	idx := loadIndex(s.root)
+	seen := make(map[string]bool)
+
+	for _, key := range keys {
+		info := infos[key]
+		seen[key] = true
+
+		if n, ok := idx.note(key, info); ok {
+			notes = append(notes, noteEntry{key, n})
+			continue
 		}
 
+		path := s.Path(key)
 		n, err := ParseNoteFile(path)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to parse note file at path '%s': %v\n", path, err)
-			return nil
+			continue
 		}
+		idx.put(key, info, n)
 
-		notes = append(notes, n)
-		return nil
-	})
+		notes = append(notes, noteEntry{key, n})
+	}
 
+	idx.prune(seen)
+	if err := idx.save(s.root); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	return notes, nil
+}
+
+// loadNotesIn parses every note whose key starts with prefix, without using the note index.
+func loadNotesIn(s Store, prefix string) ([]noteEntry, error) {
+	keys, err := s.List(prefix)
 	if err != nil {
-		return notes, fmt.Errorf("failed to walk notes directory at path '%s': %w", noteDir, err)
+		return nil, err
 	}
 
+	var notes []noteEntry
+	for _, key := range keys {
+		data, err := s.Get(key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read note '%s': %v\n", key, err)
+			continue
+		}
+		n, err := ParseNote(data, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to parse note '%s': %v\n", key, err)
+			continue
+		}
+		notes = append(notes, noteEntry{key, n})
+	}
 	return notes, nil
 }
@@ -0,0 +1,118 @@
+package jot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ErrLocked is returned when another jot process holds a lock on the vault or note.
+var ErrLocked = errors.New("locked by another jot process")
+
+// Lock is a set of advisory locks held on a vault. Locks are released by Unlock,
+// or automatically by the operating system when the process exits.
+type Lock struct {
+	files []*os.File
+}
+
+// LockVault takes an exclusive lock on the whole vault, for operations that
+// read and rewrite many notes at once such as sync.
+// It fails immediately with an error wrapping ErrLocked if any other jot process
+// holds a vault or note lock.
+func LockVault(baseDir string) (*Lock, error) {
+	l := &Lock{}
+	if err := l.acquire(filepath.Join(baseDir, "locks", "vault.lock"), true, false); err != nil {
+		return nil, fmt.Errorf("vault at '%s' is %w", baseDir, err)
+	}
+	return l, nil
+}
+
+// LockVaults takes exclusive locks on several vaults at once, for operations such as sync that
+// write to more than one. The vaults are locked in order of their absolute paths, so that two
+// processes locking the same vaults never each hold one while waiting for the other.
+// Either every vault is locked or, on error, none is.
+func LockVaults(baseDirs ...string) (*Lock, error) {
+	dirs := make([]string, 0, len(baseDirs))
+	for _, dir := range baseDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve storage path '%s': %w", dir, err)
+		}
+		if !slices.Contains(dirs, abs) {
+			dirs = append(dirs, abs)
+		}
+	}
+	slices.Sort(dirs)
+
+	l := &Lock{}
+	for _, dir := range dirs {
+		if err := l.acquire(filepath.Join(dir, "locks", "vault.lock"), true, false); err != nil {
+			_ = l.Unlock()
+			return nil, fmt.Errorf("vault at '%s' is %w", dir, err)
+		}
+	}
+	return l, nil
+}
+
+// LockNote takes an exclusive lock on a single note, together with a shared lock on the vault,
+// for read-modify-write operations on that note such as edit.
+// It fails immediately with an error wrapping ErrLocked if another jot process holds
+// the note lock or an exclusive vault lock.
+func LockNote(baseDir, id string) (*Lock, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid note ID '%s'", id)
+	}
+	l := &Lock{}
+	if err := l.acquire(filepath.Join(baseDir, "locks", "vault.lock"), false, false); err != nil {
+		return nil, fmt.Errorf("vault at '%s' is %w", baseDir, err)
+	}
+	if err := l.acquire(filepath.Join(baseDir, "locks", id+".lock"), true, false); err != nil {
+		_ = l.Unlock()
+		return nil, fmt.Errorf("note '%s' is %w", id, err)
+	}
+	return l, nil
+}
+
+// Unlock releases every lock held.
+func (l *Lock) Unlock() error {
+	var errs []error
+	for i := len(l.files) - 1; i >= 0; i-- {
+		if err := unlockFile(l.files[i]); err != nil {
+			errs = append(errs, err)
+		}
+		if err := l.files[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	l.files = nil
+	return errors.Join(errs...)
+}
+
+// waitLock takes an exclusive lock on the lock file with the given name, waiting for
+// other jot processes to release it. It is used to serialise short writes, not whole commands.
+func waitLock(baseDir, name string) (*Lock, error) {
+	l := &Lock{}
+	if err := l.acquire(filepath.Join(baseDir, "locks", name), true, true); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// acquire opens the lock file at path and locks it, waiting for other holders only if wait is set.
+func (l *Lock) acquire(path string, exclusive, wait bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create lock directory at path '%s': %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file at path '%s': %w", path, err)
+	}
+	if err := lockFile(f, exclusive, wait); err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.files = append(l.files, f)
+	return nil
+}
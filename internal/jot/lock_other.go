@@ -0,0 +1,15 @@
+//go:build !unix
+
+package jot
+
+import "os"
+
+// lockFile is a no-op on platforms without flock; concurrent jot processes are not detected there.
+func lockFile(f *os.File, exclusive, wait bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock.
+func unlockFile(f *os.File) error {
+	return nil
+}
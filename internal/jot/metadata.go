@@ -0,0 +1,289 @@
+package jot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// knownFields lists the frontmatter keys that jot manages itself.
+// Any other key is preserved in Note.Extra.
+var knownFields = map[string]bool{
+	"id":         true,
+	"title":      true,
+	"created_at": true,
+	"updated_at": true,
+	"tags":       true,
+	"links":      true,
+	"context":    true,
+}
+
+// Metadata holds frontmatter fields that jot does not manage itself, such as status or owner.
+// Fields keep their original order and any YAML comments attached to them, so they
+// survive a ParseNoteFile and SaveNote round trip unchanged.
+type Metadata struct {
+	pairs []metaPair
+}
+
+// metaPair is a single key/value entry in Metadata, stored as YAML nodes to keep comments.
+type metaPair struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// Keys returns the metadata keys in their original order.
+func (m *Metadata) Keys() []string {
+	keys := make([]string, 0, len(m.pairs))
+	for _, p := range m.pairs {
+		keys = append(keys, p.key.Value)
+	}
+	return keys
+}
+
+// Len returns the number of metadata fields.
+func (m *Metadata) Len() int {
+	return len(m.pairs)
+}
+
+// IsZero reports whether the metadata has no fields.
+// It is used by encoding/json to omit empty metadata.
+func (m Metadata) IsZero() bool {
+	return len(m.pairs) == 0
+}
+
+// Get decodes the value stored under key.
+// Returns false if the key is not present.
+func (m *Metadata) Get(key string) (any, bool) {
+	v := m.node(key)
+	if v == nil {
+		return nil, false
+	}
+	var out any
+	if err := v.Decode(&out); err != nil {
+		return v.Value, true
+	}
+	return out, true
+}
+
+// GetString returns the value stored under key as a string.
+// Sequences are joined with commas; mappings are rendered as inline YAML.
+// Returns false if the key is not present.
+func (m *Metadata) GetString(key string) (string, bool) {
+	v := m.node(key)
+	if v == nil {
+		return "", false
+	}
+	return nodeString(v), true
+}
+
+// GetStrings returns the value stored under key as a list of strings.
+// A sequence yields one string per element and a scalar yields a single string.
+// Returns nil if the key is not present.
+func (m *Metadata) GetStrings(key string) []string {
+	v := m.node(key)
+	if v == nil {
+		return nil
+	}
+	if v.Kind != yaml.SequenceNode {
+		return []string{nodeString(v)}
+	}
+	items := make([]string, 0, len(v.Content))
+	for _, item := range v.Content {
+		items = append(items, nodeString(item))
+	}
+	return items
+}
+
+// Set stores value under key, replacing any existing value while keeping the key's position and comments.
+// Returns an error if the key is managed by jot or the value cannot be encoded.
+func (m *Metadata) Set(key string, value any) error {
+	if knownFields[key] {
+		return fmt.Errorf("field '%s' is managed by jot and cannot be stored as extra metadata", key)
+	}
+
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode metadata field '%s': %w", key, err)
+	}
+
+	for i := range m.pairs {
+		if m.pairs[i].key.Value == key {
+			v.LineComment = m.pairs[i].value.LineComment
+			m.pairs[i].value = &v
+			return nil
+		}
+	}
+
+	m.pairs = append(m.pairs, metaPair{
+		key:   &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value: &v,
+	})
+	return nil
+}
+
+// Delete removes key from the metadata. It is a no-op if the key is not present.
+func (m *Metadata) Delete(key string) {
+	for i := range m.pairs {
+		if m.pairs[i].key.Value == key {
+			m.pairs = append(m.pairs[:i], m.pairs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Matches reports whether the field stored under key matches value.
+// Scalars are compared as strings; sequences match if any element matches.
+// An empty value only checks that the key is present.
+func (m *Metadata) Matches(key, value string) bool {
+	v := m.node(key)
+	if v == nil {
+		return false
+	}
+	if value == "" {
+		return true
+	}
+	if v.Kind == yaml.SequenceNode {
+		for _, item := range v.Content {
+			if nodeString(item) == value {
+				return true
+			}
+		}
+		return false
+	}
+	return nodeString(v) == value
+}
+
+// MarshalJSON encodes the metadata as a JSON object, keeping the original key order.
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range m.pairs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(p.key.Value)
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		if err := p.value.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata field '%s': %w", p.key.Value, err)
+		}
+		val, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode metadata field '%s' as JSON: %w", p.key.Value, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// node returns the value node stored under key, or nil if it is not present.
+func (m *Metadata) node(key string) *yaml.Node {
+	for _, p := range m.pairs {
+		if p.key.Value == key {
+			return p.value
+		}
+	}
+	return nil
+}
+
+// nodeString renders a YAML node as a plain string for display and comparison.
+func nodeString(v *yaml.Node) string {
+	switch v.Kind {
+	case yaml.ScalarNode:
+		return v.Value
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(v.Content))
+		for _, item := range v.Content {
+			items = append(items, nodeString(item))
+		}
+		return strings.Join(items, ",")
+	case yaml.AliasNode:
+		return nodeString(v.Alias)
+	default:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+}
+
+// splitFrontmatter separates the jot-managed keys from the rest of a frontmatter mapping node.
+// The unknown keys are returned as Metadata, preserving their order and comments.
+func splitFrontmatter(mapping *yaml.Node) Metadata {
+	var extra Metadata
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if knownFields[key.Value] {
+			continue
+		}
+		extra.pairs = append(extra.pairs, metaPair{key: key, value: value})
+	}
+	return extra
+}
+
+// mergeFrontmatter builds the frontmatter mapping node written by ToMarkdown.
+// Keys keep the order (and comments) of the original frontmatter when there was one,
+// with jot-managed values refreshed from known and extra fields taken from the note.
+// New keys are appended, managed keys first.
+func mergeFrontmatter(original, known *yaml.Node, extra Metadata) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	emitted := make(map[string]bool)
+
+	lookup := func(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if m.Content[i].Value == key {
+				return m.Content[i], m.Content[i+1]
+			}
+		}
+		return nil, nil
+	}
+
+	if original != nil {
+		out.HeadComment = original.HeadComment
+		out.FootComment = original.FootComment
+		for i := 0; i+1 < len(original.Content); i += 2 {
+			key, oldValue := original.Content[i], original.Content[i+1]
+			if emitted[key.Value] {
+				continue
+			}
+			var value *yaml.Node
+			if knownFields[key.Value] {
+				if _, v := lookup(known, key.Value); v != nil {
+					value = v
+					value.LineComment = oldValue.LineComment
+				}
+			} else {
+				value = extra.node(key.Value)
+			}
+			if value == nil {
+				continue
+			}
+			out.Content = append(out.Content, key, value)
+			emitted[key.Value] = true
+		}
+	}
+
+	for i := 0; i+1 < len(known.Content); i += 2 {
+		if !emitted[known.Content[i].Value] {
+			out.Content = append(out.Content, known.Content[i], known.Content[i+1])
+			emitted[known.Content[i].Value] = true
+		}
+	}
+	for _, p := range extra.pairs {
+		if !emitted[p.key.Value] {
+			out.Content = append(out.Content, p.key, p.value)
+			emitted[p.key.Value] = true
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,128 @@
+package jot
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// vaultAreas are the parts of a store copied when migrating between backends.
+var vaultAreas = []string{notesArea, archiveArea, trashArea, historyArea}
+
+// MigrateBackend converts the vault in cfg's storage path to the given backend.
+// Every note, archived and trashed note and revision is copied byte for byte and
+// verified before the old copy is removed, and cfg is switched to the new store.
+// The config file is not changed. Returns the number of documents migrated.
+func MigrateBackend(cfg *Config, backend string) (int, error) {
+	current := cfg.StorageBackend
+	if current == "" {
+		current = BackendFiles
+	}
+	if backend != BackendFiles && backend != BackendLog {
+		return 0, fmt.Errorf("unknown storage backend '%s': use '%s' or '%s'", backend, BackendFiles, BackendLog)
+	}
+	if backend == current {
+		return 0, fmt.Errorf("storage path '%s' already uses the '%s' backend", cfg.StoragePath, backend)
+	}
+
+	from := cfg.Store()
+	to := NewStore(cfg.StoragePath, backend)
+
+	docs := make(map[string][]byte)
+	for _, area := range vaultAreas {
+		existing, err := to.List(area)
+		if err != nil {
+			return 0, err
+		}
+		if len(existing) > 0 {
+			return 0, fmt.Errorf("cannot migrate to the '%s' backend: it already holds %d document(s) under '%s'", backend, len(existing), area)
+		}
+
+		keys, err := from.List(area)
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range keys {
+			data, err := from.Get(key)
+			if err != nil {
+				return 0, err
+			}
+			docs[key] = data
+		}
+	}
+
+	if ls, ok := to.(*LogStore); ok {
+		if err := ls.putAll(docs); err != nil {
+			return 0, err
+		}
+	} else {
+		for key, data := range docs {
+			if err := to.Put(key, data); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	for key, data := range docs {
+		copied, err := to.Get(key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to verify migrated document '%s': %w", key, err)
+		}
+		if !bytes.Equal(copied, data) {
+			return 0, fmt.Errorf("failed to verify migrated document '%s': contents differ", key)
+		}
+	}
+
+	if err := removeStore(cfg.StoragePath, from); err != nil {
+		return len(docs), err
+	}
+
+	cfg.StorageBackend = backend
+	cfg.UseStore(to)
+	return len(docs), nil
+}
+
+// removeStore deletes the documents of a store that has been migrated away from.
+func removeStore(root string, s Store) error {
+	switch s := s.(type) {
+	case *LogStore:
+		if err := os.Remove(s.Path()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log file at path '%s': %w", s.Path(), err)
+		}
+		return nil
+	case *FileStore:
+		for _, area := range vaultAreas {
+			keys, err := s.List(area)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if err := s.Delete(key); err != nil {
+					return err
+				}
+			}
+			removeEmptyDirs(filepath.Join(root, filepath.FromSlash(area)))
+		}
+		if err := os.Remove(IndexPath(root)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove note index at path '%s': %w", IndexPath(root), err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// removeEmptyDirs removes dir and any directories below it that hold no files.
+func removeEmptyDirs(dir string) {
+	var dirs []string
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+}
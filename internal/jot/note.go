@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -14,6 +14,8 @@ import (
 type Note struct {
 	// ID is the unique identifier for the note.
 	ID string `yaml:"id" json:"id"`
+	// Title is the title set in the frontmatter. When empty, the title is derived from the content; see DisplayTitle.
+	Title string `yaml:"title,omitempty" json:"title,omitempty"`
 	// CreatedAt is the timestamp when the note was created.
 	CreatedAt time.Time `yaml:"created_at" json:"created_at"`
 	// UpdatedAt is the timestamp when the note was last updated.
@@ -26,6 +28,12 @@ type Note struct {
 	Content string `yaml:"-" json:"content"`
 	// Context is the organizational context the note belongs to.
 	Context string `yaml:"context,omitempty" json:"context,omitempty"`
+	// Extra holds any additional frontmatter fields not managed by jot.
+	Extra Metadata `yaml:"-" json:"extra,omitzero"`
+
+	// frontmatter is the mapping node parsed from the note file, if any.
+	// It is used to keep the original key order and comments when the note is saved.
+	frontmatter *yaml.Node
 }
 
 // ToMarkdown converts a Note to a markdown string with YAML frontmatter.
@@ -34,6 +42,7 @@ type Note struct {
 func (n *Note) ToMarkdown() (string, error) {
 	meta := struct {
 		ID        string    `yaml:"id"`
+		Title     string    `yaml:"title,omitempty"`
 		CreatedAt time.Time `yaml:"created_at"`
 		UpdatedAt time.Time `yaml:"updated_at"`
 		Tags      []string  `yaml:"tags,omitempty"`
@@ -41,6 +50,7 @@ func (n *Note) ToMarkdown() (string, error) {
 		Context   string    `yaml:"context,omitempty"`
 	}{
 		ID:        n.ID,
+		Title:     n.Title,
 		CreatedAt: n.CreatedAt,
 		UpdatedAt: n.UpdatedAt,
 		Tags:      n.Tags,
@@ -48,9 +58,14 @@ func (n *Note) ToMarkdown() (string, error) {
 		Context:   n.Context,
 	}
 
+	var known yaml.Node
+	if err := known.Encode(meta); err != nil {
+		return "", fmt.Errorf("failed to marshal note metadata to YAML for note ID '%s': %w", n.ID, err)
+	}
+
 	var buf bytes.Buffer
 	buf.WriteString("---\n")
-	yml, err := yaml.Marshal(meta)
+	yml, err := yaml.Marshal(mergeFrontmatter(n.frontmatter, &known, n.Extra))
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal note metadata to YAML for note ID '%s': %w", n.ID, err)
 	}
@@ -62,11 +77,31 @@ func (n *Note) ToMarkdown() (string, error) {
 	return buf.String(), nil
 }
 
-// SaveNote saves a note to the notes directory.
-// It converts the note to markdown format and writes it to a file.
+// SaveNote saves a note to the notes area of the configured store.
+// It converts the note to markdown format and stores it under the key for the configured
+// filename format. If the note is stored under another key, such as a filename with an old
+// title, it is moved.
+// If link syncing is enabled, inline [[...]] references are added to the note's links first.
+// Every save that changes the note is also recorded as a revision in the note's history,
+// once the note itself has been written, and the oldest revisions beyond the configured
+// history limit are pruned.
 func SaveNote(cfg *Config, note *Note) error {
-	if err := cfg.EnsureDirectories(); err != nil {
-		return fmt.Errorf("failed to ensure directories exist for saving note ID '%s': %w", note.ID, err)
+	return saveNote(cfg, note, "")
+}
+
+// saveNote saves a note like SaveNote. current is the key the note is stored under, if the caller
+// already knows it, or "" to look it up.
+func saveNote(cfg *Config, note *Note, current string) error {
+	s := cfg.Store()
+
+	if !ValidID(note.ID) {
+		return fmt.Errorf("invalid note ID '%s': IDs must be a single file name without spaces or glob characters", note.ID)
+	}
+
+	if cfg.SyncLinks {
+		if err := SyncInlineLinks(s, note); err != nil {
+			return fmt.Errorf("failed to sync links for note ID '%s': %w", note.ID, err)
+		}
 	}
 
 	md, err := note.ToMarkdown()
@@ -74,9 +109,26 @@ func SaveNote(cfg *Config, note *Note) error {
 		return fmt.Errorf("failed to convert note ID '%s' to markdown: %w", note.ID, err)
 	}
 
-	notePath := filepath.Join(cfg.NotesDir(), fmt.Sprintf("%s.md", note.ID))
-	if err := os.WriteFile(notePath, []byte(md), 0644); err != nil {
-		return fmt.Errorf("failed to write note ID '%s' to file path '%s': %w", note.ID, notePath, err)
+	key := cfg.NoteKeyFor(note)
+	if current == "" {
+		if current, err = findNoteKey(s, note.ID, key); err != nil {
+			return fmt.Errorf("failed to find note ID '%s': %w", note.ID, err)
+		}
+	}
+	if err := s.Put(key, []byte(md)); err != nil {
+		return fmt.Errorf("failed to write note ID '%s': %w", note.ID, err)
+	}
+	if current != "" && current != key {
+		if err := s.Delete(current); err != nil {
+			return fmt.Errorf("failed to remove old file of note ID '%s': %w", note.ID, err)
+		}
+	}
+
+	if _, err := RecordRevision(s, note.ID, md); err != nil {
+		return fmt.Errorf("failed to record revision for note ID '%s': %w", note.ID, err)
+	}
+	if _, err := PruneRevisions(s, note.ID, cfg.revisionsKept()); err != nil {
+		return fmt.Errorf("failed to prune revisions of note ID '%s': %w", note.ID, err)
 	}
 	return nil
 }
@@ -89,7 +141,12 @@ func ParseNoteFile(path string) (*Note, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read note file at path '%s': %w", path, err)
 	}
+	return ParseNote(data, path)
+}
 
+// ParseNote converts markdown with YAML frontmatter to a Note.
+// The path is only used in error messages.
+func ParseNote(data []byte, path string) (*Note, error) {
 	parts := strings.SplitN(string(data), "---\n", 3)
 	if len(parts) < 3 {
 		return nil, fmt.Errorf("invalid frontmatter format in note file '%s': missing YAML delimiters", path)
@@ -98,10 +155,28 @@ func ParseNoteFile(path string) (*Note, error) {
 	metaPart := parts[1]
 	content := strings.TrimSpace(parts[2])
 
-	n := &Note{}
-	if err := yaml.Unmarshal([]byte(metaPart), n); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(metaPart), &doc); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML frontmatter in note file '%s': %w", path, err)
 	}
+
+	n := &Note{}
+	if len(doc.Content) > 0 {
+		mapping := doc.Content[0]
+		if mapping.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("invalid frontmatter in note file '%s': expected a YAML mapping", path)
+		}
+		if err := mapping.Decode(n); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML frontmatter in note file '%s': %w", path, err)
+		}
+		mapping.HeadComment = joinComments(doc.HeadComment, mapping.HeadComment)
+		mapping.FootComment = joinComments(mapping.FootComment, doc.FootComment)
+		n.frontmatter = mapping
+		n.Extra = splitFrontmatter(mapping)
+	}
+	if n.ID != "" && !ValidID(n.ID) {
+		return nil, fmt.Errorf("invalid note ID '%s' in note file '%s'", n.ID, path)
+	}
 	n.Content = content
 	if n.Tags == nil {
 		n.Tags = []string{}
@@ -113,8 +188,128 @@ func ParseNoteFile(path string) (*Note, error) {
 	return n, nil
 }
 
+// DisplayTitle returns the note's title: the title field if it is set, otherwise the title
+// derived from the content's first line of text. See DeriveTitle.
+func (n *Note) DisplayTitle() string {
+	if title := strings.TrimSpace(n.Title); title != "" {
+		return title
+	}
+	return DeriveTitle(n.Content)
+}
+
+// Field returns a frontmatter field of the note as a string, whether it is managed by jot or
+// stored in Extra. Lists are joined with commas. Returns false if the field is not set.
+func (n *Note) Field(key string) (string, bool) {
+	switch key {
+	case "id":
+		return n.ID, true
+	case "title":
+		return n.Title, n.Title != ""
+	case "created_at":
+		return n.CreatedAt.Format(time.RFC3339), true
+	case "updated_at":
+		return n.UpdatedAt.Format(time.RFC3339), true
+	case "tags":
+		return strings.Join(n.Tags, ","), len(n.Tags) > 0
+	case "links":
+		return strings.Join(n.Links, ","), len(n.Links) > 0
+	case "context":
+		return n.Context, n.Context != ""
+	}
+	return n.Extra.GetString(key)
+}
+
+// MatchesField reports whether the note has a frontmatter field, managed by jot or stored in
+// Extra, and whether it has the given value if value is not empty. A list field, such as tags,
+// matches if any of its items equals value.
+func (n *Note) MatchesField(key, value string) bool {
+	switch key {
+	case "tags":
+		return len(n.Tags) > 0 && (value == "" || slices.Contains(n.Tags, value))
+	case "links":
+		return len(n.Links) > 0 && (value == "" || slices.Contains(n.Links, value))
+	case "id", "title", "created_at", "updated_at", "context":
+		v, ok := n.Field(key)
+		return ok && (value == "" || v == value)
+	}
+	return n.Extra.Matches(key, value)
+}
+
+// SetField sets a frontmatter field of the note from a string, as given on the command line.
+// Tags and links are split on commas; any field jot does not manage is stored in Extra.
+// The ID and timestamps cannot be set.
+func (n *Note) SetField(key, value string) error {
+	switch key {
+	case "id", "created_at", "updated_at":
+		return fmt.Errorf("field '%s' cannot be changed", key)
+	case "title":
+		n.Title = strings.TrimSpace(value)
+	case "tags":
+		tags := splitList(value)
+		if err := ValidateTags(tags); err != nil {
+			return err
+		}
+		n.Tags = tags
+	case "links":
+		n.Links = splitList(value)
+	case "context":
+		n.Context = strings.TrimSpace(value)
+	default:
+		return n.Extra.Set(key, value)
+	}
+	return nil
+}
+
+// UnsetField removes a frontmatter field from the note. Without a title field, the title is
+// derived from the content again. The ID and timestamps cannot be removed.
+func (n *Note) UnsetField(key string) error {
+	switch key {
+	case "id", "created_at", "updated_at":
+		return fmt.Errorf("field '%s' cannot be removed", key)
+	case "title":
+		n.Title = ""
+	case "tags":
+		n.Tags = []string{}
+	case "links":
+		n.Links = []string{}
+	case "context":
+		n.Context = ""
+	default:
+		n.Extra.Delete(key)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty items and surrounding spaces.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Aliases returns the alternative names of the note, taken from the aliases (or alias)
+// frontmatter field as used by other markdown tools.
+func (n *Note) Aliases() []string {
+	return append(n.Extra.GetStrings("aliases"), n.Extra.GetStrings("alias")...)
+}
+
 // UpdateTimestamp sets the UpdatedAt field of the note to the current time.
 // This should be called whenever the note content is modified.
 func (n *Note) UpdateTimestamp() {
 	n.UpdatedAt = time.Now()
 }
+
+// joinComments combines two YAML comment blocks, skipping empty ones.
+func joinComments(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "\n" + b
+}
@@ -0,0 +1,74 @@
+package jot
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MinShortIDLength is the shortest abbreviation shown for a note ID.
+// IDs shorter than this are always shown in full.
+const MinShortIDLength = 8
+
+// ShortIDs maps note IDs to the shortest prefix that tells each apart from every other ID
+// in the same set, like git's abbreviated commit hashes.
+type ShortIDs map[string]string
+
+// AbbreviateIDs computes the short IDs of the given notes. Pass every note a reference could
+// resolve to, not just the ones being displayed, so that each short ID resolves back to its note.
+func AbbreviateIDs(notes []*Note) ShortIDs {
+	ids := make([]string, 0, len(notes))
+	seen := make(map[string]bool)
+	for _, n := range notes {
+		if n == nil || seen[n.ID] {
+			continue
+		}
+		seen[n.ID] = true
+		ids = append(ids, n.ID)
+	}
+	sort.Strings(ids)
+
+	short := make(ShortIDs, len(ids))
+	for i, id := range ids {
+		length := MinShortIDLength
+		if i > 0 {
+			length = max(length, commonPrefixLen(id, ids[i-1])+1)
+		}
+		if i < len(ids)-1 {
+			length = max(length, commonPrefixLen(id, ids[i+1])+1)
+		}
+		short[id] = id[:min(length, len(id))]
+	}
+	return short
+}
+
+// Of returns the short form of id, or id itself if it is not in the set.
+func (s ShortIDs) Of(id string) string {
+	if short, ok := s[id]; ok {
+		return short
+	}
+	return id
+}
+
+// commonPrefixLen returns the number of leading bytes a and b share.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// IDFromLine extracts a note ID from a line of jot output, such as a line of
+// list, timeline, search or pipe output. The ID is the first whitespace-delimited
+// token, skipping leading decoration like bullets or emoji and trimming brackets.
+func IDFromLine(line string) string {
+	for _, field := range strings.Fields(line) {
+		token := strings.Trim(field, "[](){}<>,;:'\"`")
+		if strings.IndexFunc(token, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) == -1 {
+			continue
+		}
+		return token
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package jot
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify turns text such as a note title into a lowercase, hyphen-separated slug
+// made of letters and digits, for example "Weekly Review: Q3" becomes "weekly-review-q3".
+func Slugify(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
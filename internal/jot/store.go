@@ -0,0 +1,91 @@
+package jot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrNotFound is returned by a Store when a document does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Key prefixes of the areas of a vault.
+const (
+	notesArea   = "notes/"
+	archiveArea = "archive/"
+	trashArea   = "trash/"
+	historyArea = "history/"
+)
+
+// Store holds the documents of a vault: notes, archived and trashed notes, and note revisions.
+// Documents are markdown files addressed by slash-separated keys relative to the vault and
+// without the .md extension, such as "notes/1a2b3c4d" or "history/1a2b3c4d/<revision>".
+type Store interface {
+	// Get returns the document with the given key, or an error wrapping ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Put creates or replaces the document with the given key.
+	Put(key string, data []byte) error
+	// Delete removes the document with the given key, or returns an error wrapping ErrNotFound.
+	Delete(key string) error
+	// List returns the sorted keys of all documents whose key starts with prefix.
+	List(prefix string) ([]string, error)
+	// Watch reports changes to documents whose key starts with prefix until ctx is done.
+	// The channel is closed when watching stops.
+	Watch(ctx context.Context, prefix string) (<-chan StoreEvent, error)
+}
+
+// StoreEvent describes a change to a document in a Store.
+type StoreEvent struct {
+	// Key is the key of the changed document.
+	Key string
+	// Deleted is true if the document was removed, and false if it was created or replaced.
+	Deleted bool
+}
+
+// fileBacked is implemented by stores that keep their documents in files under the storage path,
+// so git auto-commit can find the files behind a set of keys.
+type fileBacked interface {
+	// Files returns the paths of the files or directories holding the given keys.
+	Files(keys ...string) []string
+}
+
+// revisionKey returns the key of the directory holding the revisions of the note with the given ID.
+func revisionKey(id string) string {
+	return historyArea + id
+}
+
+// keyName returns the last element of a key.
+func keyName(key string) string {
+	return path.Base(key)
+}
+
+// Storage backends selectable with the storage_backend setting.
+const (
+	// BackendFiles keeps each note in its own markdown file. It is the default.
+	BackendFiles = "files"
+	// BackendLog keeps every note in a single append-only log file.
+	BackendLog = "log"
+)
+
+// NewStore returns the store for the given backend in a storage path.
+// An empty backend selects BackendFiles.
+func NewStore(root, backend string) Store {
+	if backend == BackendLog {
+		return NewLogStore(root)
+	}
+	return NewFileStore(root)
+}
+
+// OpenStore returns the store of an existing vault at root, detecting which backend it uses.
+func OpenStore(root string) (Store, error) {
+	if _, err := os.Stat(filepath.Join(root, LogFileName)); err == nil {
+		return NewLogStore(root), nil
+	}
+	if info, err := os.Stat(filepath.Join(root, "notes")); err == nil && info.IsDir() {
+		return NewFileStore(root), nil
+	}
+	return nil, fmt.Errorf("'%s' is not a jot storage path: no notes directory or log file found", root)
+}
@@ -0,0 +1,202 @@
+package jot
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often a FileStore or LogStore checks for changes while being watched.
+var watchInterval = time.Second
+
+// FileStore is a Store that keeps every document in its own markdown file under a root directory,
+// so the key "notes/1a2b3c4d" is the file <root>/notes/1a2b3c4d.md.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore returns a FileStore rooted at the given storage path.
+func NewFileStore(root string) *FileStore {
+	return &FileStore{root: root}
+}
+
+// Root returns the directory holding the store's files.
+func (s *FileStore) Root() string {
+	return s.root
+}
+
+// Path returns the path of the file holding the document with the given key.
+func (s *FileStore) Path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key)+".md")
+}
+
+// Get returns the document with the given key.
+func (s *FileStore) Get(key string) ([]byte, error) {
+	p := s.Path(key)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("document '%s' %w", key, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read file at path '%s': %w", p, err)
+	}
+	return data, nil
+}
+
+// Put writes the document with the given key atomically, creating its directory if needed.
+func (s *FileStore) Put(key string, data []byte) error {
+	p := s.Path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("failed to create directory at path '%s': %w", filepath.Dir(p), err)
+	}
+	if err := WriteFileAtomic(p, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file at path '%s': %w", p, err)
+	}
+	return nil
+}
+
+// Delete removes the file holding the document with the given key.
+// Directories left empty below the top-level directory of the key, such as notes/, are removed too.
+func (s *FileStore) Delete(key string) error {
+	p := s.Path(key)
+	if err := os.Remove(p); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("document '%s' %w", key, ErrNotFound)
+		}
+		return fmt.Errorf("failed to remove file at path '%s': %w", p, err)
+	}
+	for dir := path.Dir(key); strings.Contains(dir, "/"); dir = path.Dir(dir) {
+		if os.Remove(filepath.Join(s.root, filepath.FromSlash(dir))) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List returns the sorted keys of all markdown files whose key starts with prefix.
+func (s *FileStore) List(prefix string) ([]string, error) {
+	infos, err := s.scan(prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(infos))
+	for key := range infos {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Watch polls the files under prefix and reports documents that were created, changed or removed.
+func (s *FileStore) Watch(ctx context.Context, prefix string) (<-chan StoreEvent, error) {
+	last, err := s.scan(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StoreEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := s.scan(prefix)
+			if err != nil {
+				continue
+			}
+			var changes []StoreEvent
+			for key, info := range current {
+				if old, ok := last[key]; !ok || old.Size() != info.Size() || !old.ModTime().Equal(info.ModTime()) {
+					changes = append(changes, StoreEvent{Key: key})
+				}
+			}
+			for key := range last {
+				if _, ok := current[key]; !ok {
+					changes = append(changes, StoreEvent{Key: key, Deleted: true})
+				}
+			}
+			last = current
+
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+			for _, e := range changes {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Files returns the paths behind the given keys: a directory if the key names one, such as
+// the revisions of a note, and otherwise the document's markdown file.
+func (s *FileStore) Files(keys ...string) []string {
+	paths := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		dir := filepath.Join(s.root, filepath.FromSlash(key))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			paths = append(paths, dir)
+			continue
+		}
+		paths = append(paths, s.Path(key))
+	}
+	return paths
+}
+
+// scan returns the file info of every markdown file whose key starts with prefix, keyed by key.
+func (s *FileStore) scan(prefix string) (map[string]fs.FileInfo, error) {
+	dir := strings.TrimSuffix(prefix, "/")
+	if !strings.HasSuffix(prefix, "/") {
+		dir = path.Dir(prefix)
+	}
+	walkRoot := filepath.Join(s.root, filepath.FromSlash(dir))
+
+	infos := make(map[string]fs.FileInfo)
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == walkRoot && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return nil
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		infos[key] = info
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory at path '%s': %w", walkRoot, err)
+	}
+	return infos, nil
+}
@@ -0,0 +1,397 @@
+package jot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogFileName is the name of the file a LogStore keeps its documents in, inside the storage path.
+const LogFileName = "notes.log"
+
+// logVersion is the version written in the header record of a log file.
+const logVersion = 1
+
+// Record operations in a log file.
+const (
+	logHeader = "jot-log"
+	logPut    = "put"
+	logDelete = "delete"
+)
+
+// compactMinGarbage is the number of superseded records a log file must hold before it is compacted.
+// Compaction also waits until superseded records outnumber live documents.
+const compactMinGarbage = 256
+
+// logRecord is a single line of a log file.
+type logRecord struct {
+	Op      string    `json:"op"`
+	Key     string    `json:"key,omitempty"`
+	Time    time.Time `json:"time,omitzero"`
+	Data    []byte    `json:"data,omitempty"`
+	Version int       `json:"version,omitempty"`
+}
+
+// LogStore is a Store that keeps every document in a single append-only log file.
+// Each change appends a JSON record; the latest record for a key wins. The file is
+// rewritten without superseded records once they outnumber the live documents.
+// Several jot processes can share a LogStore: appends are serialised with a lock file,
+// and each process picks up records appended by others before reading.
+type LogStore struct {
+	root string
+	path string
+
+	mu      sync.Mutex
+	docs    map[string][]byte
+	info    fs.FileInfo
+	offset  int64
+	records int
+}
+
+// NewLogStore returns a LogStore keeping its log file in the given storage path.
+// The file is created on the first write.
+func NewLogStore(root string) *LogStore {
+	return &LogStore{root: root, path: filepath.Join(root, LogFileName), docs: make(map[string][]byte)}
+}
+
+// Path returns the path of the log file.
+func (s *LogStore) Path() string {
+	return s.path
+}
+
+// Get returns the latest version of the document with the given key.
+func (s *LogStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	data, ok := s.docs[key]
+	if !ok {
+		return nil, fmt.Errorf("document '%s' %w", key, ErrNotFound)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Put appends a new version of the document with the given key.
+func (s *LogStore) Put(key string, data []byte) error {
+	return s.putAll(map[string][]byte{key: data})
+}
+
+// Delete appends a record removing the document with the given key.
+func (s *LogStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.append(func() ([]logRecord, error) {
+		if _, ok := s.docs[key]; !ok {
+			return nil, fmt.Errorf("document '%s' %w", key, ErrNotFound)
+		}
+		return []logRecord{{Op: logDelete, Key: key, Time: time.Now().UTC()}}, nil
+	})
+}
+
+// List returns the sorted keys of all documents whose key starts with prefix.
+func (s *LogStore) List(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for key := range s.docs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Watch polls the log file and reports documents under prefix that were created, changed or removed,
+// whether by this process or another one.
+func (s *LogStore) Watch(ctx context.Context, prefix string) (<-chan StoreEvent, error) {
+	last, err := s.snapshot(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StoreEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := s.snapshot(prefix)
+			if err != nil {
+				continue
+			}
+			var changes []StoreEvent
+			for key, data := range current {
+				if old, ok := last[key]; !ok || !bytes.Equal(old, data) {
+					changes = append(changes, StoreEvent{Key: key})
+				}
+			}
+			for key := range last {
+				if _, ok := current[key]; !ok {
+					changes = append(changes, StoreEvent{Key: key, Deleted: true})
+				}
+			}
+			last = current
+
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+			for _, e := range changes {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Files returns the log file, which holds every document.
+func (s *LogStore) Files(keys ...string) []string {
+	return []string{s.path}
+}
+
+// Compact rewrites the log file so that it holds only the latest version of each live document.
+func (s *LogStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := waitLock(s.root, "log.lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	return s.compact()
+}
+
+// putAll appends new versions of several documents with a single write.
+func (s *LogStore) putAll(docs map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.append(func() ([]logRecord, error) {
+		keys := make([]string, 0, len(docs))
+		for key := range docs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		now := time.Now().UTC()
+		records := make([]logRecord, 0, len(keys))
+		for _, key := range keys {
+			records = append(records, logRecord{Op: logPut, Key: key, Time: now, Data: docs[key]})
+		}
+		return records, nil
+	})
+}
+
+// snapshot returns the documents under prefix as they are now.
+func (s *LogStore) snapshot(prefix string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	docs := make(map[string][]byte)
+	for key, data := range s.docs {
+		if strings.HasPrefix(key, prefix) {
+			docs[key] = data
+		}
+	}
+	return docs, nil
+}
+
+// append writes the records built by build to the end of the log file while holding the log lock,
+// after catching up with records appended by other processes. The caller must hold s.mu.
+func (s *LogStore) append(build func() ([]logRecord, error)) error {
+	lock, err := waitLock(s.root, "log.lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	records, err := build()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if s.offset == 0 {
+		if err := writeLogRecord(&buf, logRecord{Op: logHeader, Version: logVersion}); err != nil {
+			return err
+		}
+	}
+	for _, r := range records {
+		if err := writeLogRecord(&buf, r); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(s.root, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory at path '%s': %w", s.root, err)
+	}
+	created := s.info == nil
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file at path '%s': %w", s.path, err)
+	}
+	// Drop any partial record left behind by a process that crashed while appending.
+	if err := f.Truncate(s.offset); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to truncate log file at path '%s': %w", s.path, err)
+	}
+	if _, err := f.WriteAt(buf.Bytes(), s.offset); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to append to log file at path '%s': %w", s.path, err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync log file at path '%s': %w", s.path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close log file at path '%s': %w", s.path, err)
+	}
+	if created {
+		syncDir(s.root)
+	}
+
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if garbage := s.records - len(s.docs); garbage >= compactMinGarbage && garbage > len(s.docs) {
+		return s.compact()
+	}
+	return nil
+}
+
+// compact rewrites the log file from the documents in memory. The caller must hold s.mu and the log lock.
+func (s *LogStore) compact() error {
+	keys := make([]string, 0, len(s.docs))
+	for key := range s.docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	if err := writeLogRecord(&buf, logRecord{Op: logHeader, Version: logVersion}); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, key := range keys {
+		if err := writeLogRecord(&buf, logRecord{Op: logPut, Key: key, Time: now, Data: s.docs[key]}); err != nil {
+			return err
+		}
+	}
+
+	if err := WriteFileAtomic(s.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to compact log file at path '%s': %w", s.path, err)
+	}
+	s.info = nil
+	return s.refresh()
+}
+
+// refresh brings the documents in memory up to date with the log file, reading only the records
+// appended since the last refresh unless the file was replaced. The caller must hold s.mu.
+func (s *LogStore) refresh() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.docs, s.info, s.offset, s.records = make(map[string][]byte), nil, 0, 0
+			return nil
+		}
+		return fmt.Errorf("failed to open log file at path '%s': %w", s.path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat log file at path '%s': %w", s.path, err)
+	}
+	if s.info == nil || !os.SameFile(s.info, info) || info.Size() < s.offset {
+		s.docs, s.offset, s.records = make(map[string][]byte), 0, 0
+	}
+	s.info = info
+	if info.Size() == s.offset {
+		return nil
+	}
+
+	if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read log file at path '%s': %w", s.path, err)
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A record without a trailing newline is still being written, or was torn by a crash.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read log file at path '%s': %w", s.path, err)
+		}
+
+		var rec logRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("corrupt record at offset %d in log file '%s': %w", s.offset, s.path, err)
+		}
+		switch rec.Op {
+		case logHeader:
+			if rec.Version != logVersion {
+				return fmt.Errorf("log file '%s' has unsupported version %d", s.path, rec.Version)
+			}
+		case logPut:
+			s.docs[rec.Key] = rec.Data
+			s.records++
+		case logDelete:
+			delete(s.docs, rec.Key)
+			s.records++
+		default:
+			return fmt.Errorf("unknown record '%s' at offset %d in log file '%s'", rec.Op, s.offset, s.path)
+		}
+		s.offset += int64(len(line))
+	}
+}
+
+// writeLogRecord encodes r as a single line.
+func writeLogRecord(w io.Writer, r logRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode log record: %w", err)
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
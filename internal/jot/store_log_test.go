@@ -0,0 +1,134 @@
+package jot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// logLines returns the records of a log file, one per line.
+func logLines(t *testing.T, s *LogStore) []string {
+	t.Helper()
+	data, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestLogStoreTornRecord(t *testing.T) {
+	root := t.TempDir()
+	s := NewLogStore(root)
+	if err := s.Put("notes/a", []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A process that crashed while appending leaves a record without its newline.
+	f, err := os.OpenFile(s.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"op":"put","key":"notes/torn","da`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewLogStore(root)
+	if keys, err := reader.List("notes/"); err != nil || !slices.Equal(keys, []string{"notes/a"}) {
+		t.Fatalf("List with a torn record = %v, %v; want [notes/a]", keys, err)
+	}
+
+	// The next append drops the torn record before writing.
+	if err := reader.Put("notes/b", []byte("second")); err != nil {
+		t.Fatalf("Put after a torn record: %v", err)
+	}
+	for _, line := range logLines(t, reader) {
+		if strings.Contains(line, "notes/torn") {
+			t.Fatalf("torn record was not dropped: %s", line)
+		}
+	}
+	fresh := NewLogStore(root)
+	if keys, err := fresh.List("notes/"); err != nil || !slices.Equal(keys, []string{"notes/a", "notes/b"}) {
+		t.Fatalf("List after appending = %v, %v; want [notes/a notes/b]", keys, err)
+	}
+}
+
+func TestLogStoreCorruptRecord(t *testing.T) {
+	root := t.TempDir()
+	s := NewLogStore(root)
+	if err := s.Put("notes/a", []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(s.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("not json\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewLogStore(root).Get("notes/a"); err == nil || !strings.Contains(err.Error(), "corrupt record") {
+		t.Fatalf("Get from a corrupt log: got %v, want a corrupt record error", err)
+	}
+}
+
+func TestLogStoreSharedBetweenProcesses(t *testing.T) {
+	root := t.TempDir()
+	a, b := NewLogStore(root), NewLogStore(root)
+
+	if err := a.Put("notes/x", []byte("from a")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := b.Get("notes/x"); err != nil || string(data) != "from a" {
+		t.Fatalf("b.Get = %q, %v; want the document written by a", data, err)
+	}
+	if err := b.Delete("notes/x"); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := a.List("notes/"); len(keys) != 0 {
+		t.Fatalf("a.List after b deleted = %v, want none", keys)
+	}
+}
+
+func TestLogStoreCompaction(t *testing.T) {
+	root := t.TempDir()
+	s := NewLogStore(root)
+
+	// Rewriting one document piles up superseded records until the log compacts itself.
+	for i := 0; i <= compactMinGarbage+1; i++ {
+		if err := s.Put("notes/a", []byte(fmt.Sprintf("version %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines := logLines(t, s); len(lines) > compactMinGarbage {
+		t.Fatalf("log holds %d records after %d rewrites; want it compacted", len(lines), compactMinGarbage+2)
+	}
+	if data, _ := NewLogStore(root).Get("notes/a"); string(data) != fmt.Sprintf("version %d", compactMinGarbage+1) {
+		t.Fatalf("Get after compaction = %q, want the latest version", data)
+	}
+
+	if err := s.Put("notes/b", []byte("kept")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("notes/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	lines := logLines(t, s)
+	if len(lines) != 2 || !strings.Contains(lines[0], logHeader) || !strings.Contains(lines[1], `"notes/b"`) {
+		t.Fatalf("compacted log =\n%s\nwant the header and notes/b only", strings.Join(lines, "\n"))
+	}
+	if data, err := NewLogStore(root).Get("notes/b"); err != nil || !bytes.Equal(data, []byte("kept")) {
+		t.Fatalf("Get(notes/b) after Compact = %q, %v", data, err)
+	}
+}
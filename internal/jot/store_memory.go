@@ -0,0 +1,117 @@
+package jot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps every document in memory.
+// It is useful for tests and for working on a vault without touching the disk.
+type MemoryStore struct {
+	mu       sync.Mutex
+	docs     map[string][]byte
+	watchers map[*memoryWatcher]struct{}
+}
+
+// memoryWatcher is a subscriber registered by MemoryStore.Watch.
+type memoryWatcher struct {
+	prefix string
+	events chan StoreEvent
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		docs:     make(map[string][]byte),
+		watchers: make(map[*memoryWatcher]struct{}),
+	}
+}
+
+// Get returns a copy of the document with the given key.
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.docs[key]
+	if !ok {
+		return nil, fmt.Errorf("document '%s' %w", key, ErrNotFound)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Put stores a copy of the document with the given key.
+func (s *MemoryStore) Put(key string, data []byte) error {
+	s.mu.Lock()
+	s.docs[key] = append([]byte(nil), data...)
+	s.mu.Unlock()
+
+	s.notify(StoreEvent{Key: key})
+	return nil
+}
+
+// Delete removes the document with the given key.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	_, ok := s.docs[key]
+	delete(s.docs, key)
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("document '%s' %w", key, ErrNotFound)
+	}
+	s.notify(StoreEvent{Key: key, Deleted: true})
+	return nil
+}
+
+// List returns the sorted keys of all documents whose key starts with prefix.
+func (s *MemoryStore) List(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for key := range s.docs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Watch reports every Put and Delete of a document whose key starts with prefix until ctx is done.
+// Events are buffered; a watcher that falls too far behind misses events rather than blocking writers.
+func (s *MemoryStore) Watch(ctx context.Context, prefix string) (<-chan StoreEvent, error) {
+	w := &memoryWatcher{prefix: prefix, events: make(chan StoreEvent, 64)}
+
+	s.mu.Lock()
+	s.watchers[w] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.watchers, w)
+		close(w.events)
+		s.mu.Unlock()
+	}()
+	return w.events, nil
+}
+
+// notify sends an event to every watcher interested in its key.
+func (s *MemoryStore) notify(e StoreEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for w := range s.watchers {
+		if !strings.HasPrefix(e.Key, w.prefix) {
+			continue
+		}
+		select {
+		case w.events <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package jot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// storeBackends returns a fresh, empty store of every backend, by name.
+func storeBackends(t *testing.T) map[string]Store {
+	t.Helper()
+	return map[string]Store{
+		"file":   NewFileStore(t.TempDir()),
+		"memory": NewMemoryStore(),
+		"log":    NewLogStore(t.TempDir()),
+	}
+}
+
+func TestStoreContract(t *testing.T) {
+	for name, s := range storeBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Get("notes/missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get of a missing key: got %v, want ErrNotFound", err)
+			}
+			if err := s.Delete("notes/missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Delete of a missing key: got %v, want ErrNotFound", err)
+			}
+
+			docs := map[string]string{
+				"notes/b":          "second",
+				"notes/a":          "first",
+				"notes/work/c":     "nested",
+				"archive/a":        "archived",
+				"history/a/rev-01": "revision",
+			}
+			for key, data := range docs {
+				if err := s.Put(key, []byte(data)); err != nil {
+					t.Fatalf("Put(%q): %v", key, err)
+				}
+			}
+
+			got, err := s.Get("notes/a")
+			if err != nil || string(got) != "first" {
+				t.Fatalf("Get(notes/a) = %q, %v; want %q", got, err, "first")
+			}
+			got[0] = 'X'
+			if again, _ := s.Get("notes/a"); string(again) != "first" {
+				t.Fatalf("changing the result of Get changed the stored document to %q", again)
+			}
+
+			if err := s.Put("notes/a", []byte("replaced")); err != nil {
+				t.Fatalf("Put over an existing key: %v", err)
+			}
+			if got, _ := s.Get("notes/a"); string(got) != "replaced" {
+				t.Fatalf("Get after replacing = %q, want %q", got, "replaced")
+			}
+
+			keys, err := s.List("notes/")
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if want := []string{"notes/a", "notes/b", "notes/work/c"}; !slices.Equal(keys, want) {
+				t.Fatalf("List(notes/) = %v, want %v", keys, want)
+			}
+			if keys, _ := s.List("history/a/"); !slices.Equal(keys, []string{"history/a/rev-01"}) {
+				t.Fatalf("List(history/a/) = %v", keys)
+			}
+
+			if err := s.Delete("notes/work/c"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := s.Get("notes/work/c"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get after Delete: got %v, want ErrNotFound", err)
+			}
+			if keys, _ := s.List("notes/"); !slices.Equal(keys, []string{"notes/a", "notes/b"}) {
+				t.Fatalf("List after Delete = %v", keys)
+			}
+		})
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	defer func(interval time.Duration) { watchInterval = interval }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+
+	for name, s := range storeBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.Put("notes/old", []byte("old")); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events, err := s.Watch(ctx, "notes/")
+			if err != nil {
+				t.Fatalf("Watch: %v", err)
+			}
+
+			// Changes outside the prefix are not reported.
+			if err := s.Put("archive/other", []byte("x")); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Put("notes/new", []byte("new")); err != nil {
+				t.Fatal(err)
+			}
+			if e := nextEvent(t, events); e != (StoreEvent{Key: "notes/new"}) {
+				t.Fatalf("event after Put = %+v, want notes/new", e)
+			}
+
+			if err := s.Delete("notes/old"); err != nil {
+				t.Fatal(err)
+			}
+			if e := nextEvent(t, events); e != (StoreEvent{Key: "notes/old", Deleted: true}) {
+				t.Fatalf("event after Delete = %+v, want notes/old deleted", e)
+			}
+
+			cancel()
+			deadline := time.After(time.Second)
+			for {
+				select {
+				case _, ok := <-events:
+					if !ok {
+						return
+					}
+				case <-deadline:
+					t.Fatal("events channel was not closed after the context was cancelled")
+				}
+			}
+		})
+	}
+}
+
+// nextEvent waits for the next event from a watch, failing the test if none arrives in time.
+func nextEvent(t *testing.T, events <-chan StoreEvent) StoreEvent {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		if !ok {
+			t.Fatal("events channel closed unexpectedly")
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a store event")
+	}
+	return StoreEvent{}
+}
@@ -0,0 +1,472 @@
+package jot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sync action kinds.
+const (
+	SyncPull     = "pull"
+	SyncPush     = "push"
+	SyncMerge    = "merge"
+	SyncConflict = "conflict"
+	SyncTrash    = "trash"
+)
+
+// SyncAction describes what a sync did, or would do, with a single note.
+type SyncAction struct {
+	// Kind is one of SyncPull, SyncPush, SyncMerge, SyncConflict or SyncTrash.
+	Kind string `json:"kind"`
+	// ID is the note the action applies to.
+	ID string `json:"id"`
+	// Remote is true for SyncTrash when the note is trashed in the other vault.
+	Remote bool `json:"remote,omitempty"`
+	// ConflictID is the ID of the conflict copy written for SyncConflict.
+	ConflictID string `json:"conflict_id,omitempty"`
+	// Archived is true when the note ends up in the archive.
+	Archived bool `json:"archived,omitempty"`
+}
+
+// syncState is the state of the last sync with a peer vault, stored under the storage path.
+type syncState struct {
+	Peer     string            `json:"peer"`
+	LastSync time.Time         `json:"last_sync"`
+	Notes    map[string]string `json:"notes"`
+}
+
+// archivedState marks the sync state of a note that was archived when it was synced.
+const archivedState = "archived:"
+
+// syncFile is a note found in one of the vaults taking part in a sync.
+type syncFile struct {
+	key      string
+	data     []byte
+	hash     string
+	note     *Note
+	archived bool
+}
+
+// state returns what the sync state records for the file: the hash of its content, marked when
+// the note is archived so that archiving or unarchiving a note is synced like any other change.
+func (f *syncFile) state() string {
+	return noteState(f.hash, f.archived)
+}
+
+// noteState returns the sync state of a note with the given content hash, archived or not.
+func noteState(hash string, archived bool) string {
+	if archived {
+		return archivedState + hash
+	}
+	return hash
+}
+
+// vault is one side of a sync.
+type vault struct {
+	store Store
+	files map[string]*syncFile
+	// keepKeys is set for the peer vault, whose layout is unknown: notes it already has stay
+	// under their key there, and only new notes are laid out by the local config.
+	keepKeys bool
+}
+
+// keyFor returns the key the note in data goes under in the vault, in the archive if archived is set.
+// f is the note's current file in the vault, or nil if the vault does not have it yet.
+func (v *vault) keyFor(cfg *Config, f *syncFile, n *Note, archived bool) string {
+	rel := strings.TrimPrefix(cfg.NoteKeyFor(n), notesArea)
+	if f != nil && v.keepKeys {
+		rel = strings.TrimPrefix(f.key, areaOf(f.key))
+	}
+	if archived {
+		return archiveArea + rel
+	}
+	return notesArea + rel
+}
+
+// SyncVaults performs a two-way sync between the notes in cfg's store and the notes in another storage path,
+// which may use either storage backend.
+// Notes are matched by ID, both in the notes area and in the archive. New and changed notes are
+// copied in whichever direction they changed, and so is archiving or unarchiving a note. Notes
+// deleted on one side since the last sync are moved to the trash on the other, and notes
+// changed on both sides are merged line by line against the version from the last sync. When the
+// changes overlap, the most recently updated version wins and the other is kept as a conflict copy
+// on both sides. Notes are written under the key cfg's layout gives them, except that notes the
+// other vault already has keep their key there. With dryRun set, the actions are computed but
+// nothing is written.
+func SyncVaults(cfg *Config, otherBase string, dryRun bool) ([]SyncAction, error) {
+	localBase, err := filepath.Abs(cfg.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage path '%s': %w", cfg.StoragePath, err)
+	}
+	remoteBase, err := filepath.Abs(otherBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage path '%s': %w", otherBase, err)
+	}
+	if localBase == remoteBase {
+		return nil, fmt.Errorf("cannot sync storage path '%s' with itself", localBase)
+	}
+	remoteStore, err := OpenStore(remoteBase)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := scanVault(cfg.Store())
+	if err != nil {
+		return nil, err
+	}
+	remote, err := scanVault(remoteStore)
+	if err != nil {
+		return nil, err
+	}
+	remote.keepKeys = true
+
+	stateDir := filepath.Join(localBase, "sync", peerKey(remoteBase))
+	state := loadSyncState(stateDir, remoteBase)
+
+	ids := make(map[string]bool)
+	for id := range local.files {
+		ids[id] = true
+	}
+	for id := range remote.files {
+		ids[id] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	var actions []SyncAction
+	var touched []string
+	// write saves the note with the given ID to a vault where its current file is f, or nil if the
+	// vault does not have it, moving the file if the layout or the archiving calls for another key.
+	write := func(v *vault, f *syncFile, id string, data []byte, archived bool) error {
+		if dryRun {
+			return nil
+		}
+		n, err := ParseNote(data, id)
+		if err != nil {
+			return fmt.Errorf("failed to parse note ID '%s': %w", id, err)
+		}
+		key := v.keyFor(cfg, f, n, archived)
+		if f == nil || f.key != key {
+			if _, err := v.store.Get(key); err == nil {
+				return fmt.Errorf("cannot write note ID '%s': another document already exists at '%s'", id, key)
+			}
+		}
+		if err := v.store.Put(key, data); err != nil {
+			return fmt.Errorf("failed to write note ID '%s': %w", id, err)
+		}
+		if f != nil && f.key != key {
+			if err := v.store.Delete(f.key); err != nil {
+				return fmt.Errorf("failed to move note ID '%s' from '%s' to '%s': %w", id, f.key, key, err)
+			}
+		}
+		if _, err := RecordRevision(v.store, id, string(data)); err != nil {
+			return err
+		}
+		if _, err := PruneRevisions(v.store, id, cfg.revisionsKept()); err != nil {
+			return fmt.Errorf("failed to prune revisions of note ID '%s': %w", id, err)
+		}
+		if v == local {
+			touched = append(touched, key, revisionKey(id))
+			if f != nil && f.key != key {
+				touched = append(touched, f.key)
+			}
+		}
+		return nil
+	}
+	synced := func(id string, data []byte, archived bool) error {
+		value := noteState(hashNote(data), archived)
+		unchanged := state.Notes[id] == value
+		state.Notes[id] = value
+		if dryRun || unchanged {
+			return nil
+		}
+		return writeSyncBase(stateDir, id, data)
+	}
+	forget := func(id string) {
+		delete(state.Notes, id)
+		if !dryRun {
+			_ = os.Remove(filepath.Join(stateDir, "base", id+".md"))
+		}
+	}
+	// trash moves a note deleted in the other vault to the trash of v.
+	trash := func(v *vault, f *syncFile, id string) error {
+		actions = append(actions, SyncAction{Kind: SyncTrash, ID: id, Remote: v == remote})
+		forget(id)
+		if dryRun {
+			return nil
+		}
+		src, dest, err := moveNote(v.store, areaOf(f.key), trashArea, keyName(f.key))
+		if err != nil {
+			return err
+		}
+		if v == local {
+			touched = append(touched, src, dest)
+		}
+		return nil
+	}
+
+	for id := range state.Notes {
+		if !ids[id] {
+			forget(id)
+		}
+	}
+
+	for _, id := range sorted {
+		l, r := local.files[id], remote.files[id]
+		baseState, hasBase := state.Notes[id]
+
+		switch {
+		case l != nil && r != nil:
+			if l.state() == r.state() {
+				if err := synced(id, l.data, l.archived); err != nil {
+					return actions, err
+				}
+				continue
+			}
+
+			switch {
+			case hasBase && l.state() == baseState:
+				actions = append(actions, SyncAction{Kind: SyncPull, ID: id, Archived: r.archived})
+				if err := write(local, l, id, r.data, r.archived); err != nil {
+					return actions, err
+				}
+				if err := synced(id, r.data, r.archived); err != nil {
+					return actions, err
+				}
+				continue
+			case hasBase && r.state() == baseState:
+				actions = append(actions, SyncAction{Kind: SyncPush, ID: id, Archived: l.archived})
+				if err := write(remote, r, id, l.data, l.archived); err != nil {
+					return actions, err
+				}
+				if err := synced(id, l.data, l.archived); err != nil {
+					return actions, err
+				}
+				continue
+			}
+
+			// Both sides changed. If only one of them archived or unarchived the note, that change is
+			// kept; without a previous sync to tell which one did, the note stays out of the archive.
+			archived := l.archived
+			if l.archived != r.archived {
+				archived = hasBase && !strings.HasPrefix(baseState, archivedState)
+			}
+
+			if l.hash == r.hash {
+				kind, v, f, src := SyncPush, remote, r, l
+				if l.archived != archived {
+					kind, v, f, src = SyncPull, local, l, r
+				}
+				actions = append(actions, SyncAction{Kind: kind, ID: id, Archived: archived})
+				if err := write(v, f, id, src.data, archived); err != nil {
+					return actions, err
+				}
+				if err := synced(id, src.data, archived); err != nil {
+					return actions, err
+				}
+				continue
+			}
+
+			if base, err := os.ReadFile(filepath.Join(stateDir, "base", id+".md")); hasBase && err == nil {
+				if merged, conflict := MergeMarkdown(base, l.data, r.data, "local", "remote"); !conflict {
+					actions = append(actions, SyncAction{Kind: SyncMerge, ID: id, Archived: archived})
+					if err := write(local, l, id, merged, archived); err != nil {
+						return actions, err
+					}
+					if err := write(remote, r, id, merged, archived); err != nil {
+						return actions, err
+					}
+					if err := synced(id, merged, archived); err != nil {
+						return actions, err
+					}
+					continue
+				}
+			}
+
+			winner, loser := l, r
+			if r.note.UpdatedAt.After(l.note.UpdatedAt) {
+				winner, loser = r, l
+			}
+			copyID := ConflictID(id)
+			copyNote, err := ConflictCopy(loser.data, id, copyID)
+			if err != nil {
+				return actions, err
+			}
+			copyMarkdown, err := copyNote.ToMarkdown()
+			if err != nil {
+				return actions, err
+			}
+			copyData := []byte(copyMarkdown)
+			actions = append(actions, SyncAction{Kind: SyncConflict, ID: id, ConflictID: copyID, Archived: archived})
+			for _, side := range []struct {
+				v *vault
+				f *syncFile
+			}{{local, l}, {remote, r}} {
+				if side.f != winner || side.f.archived != archived {
+					if err := write(side.v, side.f, id, winner.data, archived); err != nil {
+						return actions, err
+					}
+				}
+				if err := write(side.v, nil, copyID, copyData, false); err != nil {
+					return actions, err
+				}
+			}
+			if err := synced(id, winner.data, archived); err != nil {
+				return actions, err
+			}
+			if err := synced(copyID, copyData, false); err != nil {
+				return actions, err
+			}
+
+		case l != nil:
+			if hasBase && l.state() == baseState {
+				if err := trash(local, l, id); err != nil {
+					return actions, err
+				}
+				continue
+			}
+			actions = append(actions, SyncAction{Kind: SyncPush, ID: id, Archived: l.archived})
+			if err := write(remote, nil, id, l.data, l.archived); err != nil {
+				return actions, err
+			}
+			if err := synced(id, l.data, l.archived); err != nil {
+				return actions, err
+			}
+
+		case r != nil:
+			if hasBase && r.state() == baseState {
+				if err := trash(remote, r, id); err != nil {
+					return actions, err
+				}
+				continue
+			}
+			actions = append(actions, SyncAction{Kind: SyncPull, ID: id, Archived: r.archived})
+			if err := write(local, nil, id, r.data, r.archived); err != nil {
+				return actions, err
+			}
+			if err := synced(id, r.data, r.archived); err != nil {
+				return actions, err
+			}
+		}
+	}
+
+	if dryRun {
+		return actions, nil
+	}
+
+	state.LastSync = time.Now()
+	if err := saveSyncState(stateDir, state); err != nil {
+		return actions, err
+	}
+	if len(touched) > 0 {
+		if err := CommitKeys(cfg, "jot: sync with "+remoteBase, touched...); err != nil {
+			return actions, err
+		}
+	}
+	return actions, nil
+}
+
+// scanVault reads every note in a store, in the notes area or the archive, keyed by note ID.
+func scanVault(s Store) (*vault, error) {
+	v := &vault{store: s, files: make(map[string]*syncFile)}
+
+	for _, area := range []string{notesArea, archiveArea} {
+		keys, err := s.List(area)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list notes: %w", err)
+		}
+		for _, key := range keys {
+			data, err := s.Get(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read note '%s': %w", key, err)
+			}
+			n, err := ParseNote(data, key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipping note '%s': %v\n", key, err)
+				continue
+			}
+			if n.ID == "" {
+				fmt.Fprintf(os.Stderr, "Warning: skipping note without an ID at '%s'\n", key)
+				continue
+			}
+			if f, ok := v.files[n.ID]; ok {
+				fmt.Fprintf(os.Stderr, "Warning: skipping note '%s': note ID '%s' is also stored at '%s'\n", key, n.ID, f.key)
+				continue
+			}
+			v.files[n.ID] = &syncFile{key: key, data: data, hash: hashNote(data), note: n, archived: area == archiveArea}
+		}
+	}
+	return v, nil
+}
+
+// areaOf returns the area, notes or archive, that a key scanned by scanVault is in.
+func areaOf(key string) string {
+	if strings.HasPrefix(key, archiveArea) {
+		return archiveArea
+	}
+	return notesArea
+}
+
+// hashNote returns the content hash used to detect changes, ignoring the updated_at timestamp.
+func hashNote(data []byte) string {
+	sum := sha256.Sum256([]byte(updatedAtLine.ReplaceAllString(string(data), "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// peerKey returns a short, stable directory name for a peer storage path.
+func peerKey(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadSyncState reads the state of the last sync with a peer, or returns an empty state.
+func loadSyncState(dir, peer string) *syncState {
+	state := &syncState{Peer: peer, Notes: make(map[string]string)}
+	data, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, state); err != nil || state.Notes == nil {
+		return &syncState{Peer: peer, Notes: make(map[string]string)}
+	}
+	return state
+}
+
+// saveSyncState writes the sync state for a peer.
+func saveSyncState(dir string, state *syncState) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create sync state directory at path '%s': %w", dir, err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state: %w", err)
+	}
+	path := filepath.Join(dir, "state.json")
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync state to path '%s': %w", path, err)
+	}
+	return nil
+}
+
+// writeSyncBase stores the synced version of a note, used as the base of later three-way merges.
+func writeSyncBase(dir, id string, data []byte) error {
+	baseDir := filepath.Join(dir, "base")
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create sync base directory at path '%s': %w", baseDir, err)
+	}
+	path := filepath.Join(baseDir, id+".md")
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync base for note ID '%s': %w", id, err)
+	}
+	return nil
+}
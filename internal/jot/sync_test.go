@@ -0,0 +1,228 @@
+package jot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syncVault returns the config of an empty vault in a temporary storage path.
+func syncVault(t *testing.T) *Config {
+	t.Helper()
+	cfg := &Config{StoragePath: t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(cfg.StoragePath, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// syncNote saves a note with the given ID, context and body to a vault.
+func syncNote(t *testing.T, cfg *Config, id, context, body string) {
+	t.Helper()
+	created := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	n := &Note{ID: id, Context: context, CreatedAt: created, UpdatedAt: time.Now(), Content: body}
+	if err := SaveNote(cfg, n); err != nil {
+		t.Fatalf("SaveNote(%s): %v", id, err)
+	}
+}
+
+// syncRun syncs local with remote and returns the actions as "kind id" strings, with an
+// "archived" suffix for notes that end up in the archive.
+func syncRun(t *testing.T, local, remote *Config) []string {
+	t.Helper()
+	actions, err := SyncVaults(local, remote.StoragePath, false)
+	if err != nil {
+		t.Fatalf("SyncVaults: %v", err)
+	}
+	var got []string
+	for _, a := range actions {
+		s := a.Kind + " " + a.ID
+		if a.Archived {
+			s += " archived"
+		}
+		if a.Remote {
+			s += " remote"
+		}
+		got = append(got, s)
+	}
+	return got
+}
+
+// assertKeys fails the test unless the keys in area of cfg's vault are exactly want.
+func assertKeys(t *testing.T, cfg *Config, area string, want ...string) {
+	t.Helper()
+	keys, err := cfg.Store().List(area)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(keys, want) {
+		t.Errorf("keys in %s = %v, want %v", area, keys, want)
+	}
+}
+
+func TestSyncCopiesNewNotes(t *testing.T) {
+	local, remote := syncVault(t), syncVault(t)
+	syncNote(t, local, "a", "", "from local")
+	syncNote(t, remote, "b", "", "from remote")
+
+	if got, want := syncRun(t, local, remote), []string{"push a", "pull b"}; !slices.Equal(got, want) {
+		t.Fatalf("first sync = %v, want %v", got, want)
+	}
+	if got := syncRun(t, local, remote); len(got) != 0 {
+		t.Fatalf("second sync = %v, want nothing to do", got)
+	}
+	assertKeys(t, local, notesArea, "notes/a", "notes/b")
+	assertKeys(t, remote, notesArea, "notes/a", "notes/b")
+}
+
+func TestSyncArchive(t *testing.T) {
+	local, remote := syncVault(t), syncVault(t)
+	syncNote(t, local, "a", "", "body")
+	syncRun(t, local, remote)
+
+	// Archiving on one side archives on the other, rather than looking like a deletion.
+	if _, _, err := ArchiveNote(remote.Store(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := syncRun(t, local, remote), []string{"pull a archived"}; !slices.Equal(got, want) {
+		t.Fatalf("sync after archiving = %v, want %v", got, want)
+	}
+	assertKeys(t, local, notesArea)
+	assertKeys(t, local, archiveArea, "archive/a")
+	assertKeys(t, local, trashArea)
+	assertKeys(t, remote, trashArea)
+
+	if _, _, err := UnarchiveNote(local.Store(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := syncRun(t, local, remote), []string{"push a"}; !slices.Equal(got, want) {
+		t.Fatalf("sync after unarchiving = %v, want %v", got, want)
+	}
+	assertKeys(t, remote, notesArea, "notes/a")
+	assertKeys(t, remote, archiveArea)
+
+	// A note archived on one side and edited on the other is merged and stays archived.
+	if _, _, err := ArchiveNote(local.Store(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	syncNote(t, remote, "a", "", "body\nmore")
+	if got, want := syncRun(t, local, remote), []string{"merge a archived"}; !slices.Equal(got, want) {
+		t.Fatalf("sync after archiving and editing = %v, want %v", got, want)
+	}
+	assertKeys(t, remote, archiveArea, "archive/a")
+	if data, _ := local.Store().Get("archive/a"); !strings.Contains(string(data), "more") {
+		t.Errorf("archived note = %q, want the remote edit", data)
+	}
+}
+
+func TestSyncTrashesDeletedNotes(t *testing.T) {
+	local, remote := syncVault(t), syncVault(t)
+	syncNote(t, local, "a", "", "body")
+	syncNote(t, local, "b", "", "body")
+	syncRun(t, local, remote)
+	if _, _, err := ArchiveNote(local.Store(), "b"); err != nil {
+		t.Fatal(err)
+	}
+	syncRun(t, local, remote)
+
+	if _, _, err := TrashNote(remote.Store(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Store().Delete("archive/b"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := syncRun(t, local, remote), []string{"trash a", "trash b"}; !slices.Equal(got, want) {
+		t.Fatalf("sync after deleting = %v, want %v", got, want)
+	}
+	assertKeys(t, local, notesArea)
+	assertKeys(t, local, archiveArea)
+	assertKeys(t, local, trashArea, "trash/a", "trash/b")
+}
+
+func TestSyncUsesLayout(t *testing.T) {
+	local, remote := syncVault(t), syncVault(t)
+	local.Layout = LayoutContext
+	syncNote(t, remote, "a", "work", "body")
+	syncRun(t, local, remote)
+	assertKeys(t, local, notesArea, "notes/work/a")
+
+	// The remote vault keeps the note where it already was.
+	syncNote(t, local, "a", "work", "changed")
+	if got, want := syncRun(t, local, remote), []string{"push a"}; !slices.Equal(got, want) {
+		t.Fatalf("sync after editing = %v, want %v", got, want)
+	}
+	assertKeys(t, remote, notesArea, "notes/a")
+
+	// A pulled change of context moves the local file.
+	syncNote(t, remote, "a", "home", "changed")
+	syncRun(t, local, remote)
+	assertKeys(t, local, notesArea, "notes/home/a")
+
+	// Another document at the key a note should go to is not overwritten.
+	if err := local.Store().Put("notes/b", []byte("---\nid: other\n---\n")); err != nil {
+		t.Fatal(err)
+	}
+	local.Layout = LayoutFlat
+	syncNote(t, remote, "b", "", "body")
+	if _, err := SyncVaults(local, remote.StoragePath, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("SyncVaults onto an existing document: got %v, want an error", err)
+	}
+}
+
+func TestSyncMerges(t *testing.T) {
+	local, remote := syncVault(t), syncVault(t)
+	syncNote(t, local, "a", "", "one\ntwo\nthree")
+	syncRun(t, local, remote)
+
+	syncNote(t, local, "a", "", "ONE\ntwo\nthree")
+	syncNote(t, remote, "a", "", "one\ntwo\nTHREE")
+	if got, want := syncRun(t, local, remote), []string{"merge a"}; !slices.Equal(got, want) {
+		t.Fatalf("sync after separate edits = %v, want %v", got, want)
+	}
+	for _, cfg := range []*Config{local, remote} {
+		if data, _ := cfg.Store().Get("notes/a"); !strings.Contains(string(data), "ONE\ntwo\nTHREE") {
+			t.Errorf("merged note = %q", data)
+		}
+	}
+
+	syncNote(t, local, "a", "", "mine")
+	syncNote(t, remote, "a", "", "theirs")
+	got := syncRun(t, local, remote)
+	if len(got) != 1 || got[0] != "conflict a" {
+		t.Fatalf("sync after overlapping edits = %v, want a conflict", got)
+	}
+	keys, _ := local.Store().List(notesArea)
+	if len(keys) != 2 || !strings.HasPrefix(keys[1], "notes/a-conflict-") {
+		t.Errorf("keys after conflict = %v, want the note and its conflict copy", keys)
+	}
+}
+
+func TestSyncPrunesRevisions(t *testing.T) {
+	local, remote := syncVault(t), syncVault(t)
+	local.HistoryLimit = 2
+	for i := range 4 {
+		syncNote(t, remote, "a", "", fmt.Sprintf("version %d", i))
+		syncRun(t, local, remote)
+	}
+	revs, err := ListRevisions(local.Store(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 {
+		t.Errorf("local revisions after four pulls = %d, want 2", len(revs))
+	}
+}
+
+func TestSyncWithItself(t *testing.T) {
+	local := syncVault(t)
+	if _, err := SyncVaults(local, local.StoragePath, false); err == nil {
+		t.Fatal("SyncVaults with itself succeeded")
+	}
+	if _, err := SyncVaults(local, t.TempDir(), false); err == nil {
+		t.Fatal("SyncVaults with a directory that is not a vault succeeded")
+	}
+}
@@ -0,0 +1,335 @@
+package jot
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TagCount is a tag and the number of notes that have it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// CountTags counts the notes carrying each tag, most used first and then by name.
+func CountTags(notes []*Note) []TagCount {
+	counts := make(map[string]int)
+	for _, n := range notes {
+		seen := make(map[string]bool, len(n.Tags))
+		for _, t := range n.Tags {
+			if !seen[t] {
+				seen[t] = true
+				counts[t]++
+			}
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for t, c := range counts {
+		tags = append(tags, TagCount{Tag: t, Count: c})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+// TagNode is a level of the tag hierarchy, such as atlas in proj/atlas/infra.
+type TagNode struct {
+	// Name is the last level of the tag.
+	Name string `json:"name"`
+	// Tag is the full tag.
+	Tag string `json:"tag"`
+	// Count is the number of notes with the tag or any of its descendants.
+	Count int `json:"count"`
+	// Children are the tags one level below, by name.
+	Children []*TagNode `json:"children,omitempty"`
+}
+
+// TagTree arranges the tags of the notes into their hierarchy and returns the top-level tags,
+// by name. Parents that no note is tagged with directly still appear, counting their descendants.
+func TagTree(notes []*Note) []*TagNode {
+	root := &TagNode{}
+	for _, n := range notes {
+		// Each node counts a note once, however many of its tags fall under the node.
+		seen := make(map[*TagNode]bool)
+		for _, t := range n.Tags {
+			node := root
+			levels := strings.Split(t, "/")
+			for i, name := range levels {
+				node = node.child(name, strings.Join(levels[:i+1], "/"))
+				if !seen[node] {
+					seen[node] = true
+					node.Count++
+				}
+			}
+		}
+	}
+	root.sort()
+	return root.Children
+}
+
+// child returns the child with the given name, adding it if there is none.
+func (t *TagNode) child(name, tag string) *TagNode {
+	for _, c := range t.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &TagNode{Name: name, Tag: tag}
+	t.Children = append(t.Children, c)
+	return c
+}
+
+// sort orders the descendants of t by name.
+func (t *TagNode) sort() {
+	sort.Slice(t.Children, func(i, j int) bool { return t.Children[i].Name < t.Children[j].Name })
+	for _, c := range t.Children {
+		c.sort()
+	}
+}
+
+// UnusedTags returns the tags that at most one live note carries, counted over the live notes:
+// one-off tags, which are often typos, and tags that only retired notes, such as archived or
+// trashed ones, still carry, with a count of zero. A note tagged with a descendant of a tag
+// counts as carrying the tag too. The least used tags come first, then by name.
+func UnusedTags(live, retired []*Note) []TagCount {
+	counts := make(map[string]int)
+	for _, n := range live {
+		carried := make(map[string]bool)
+		for _, t := range n.Tags {
+			for ; t != ""; t, _ = splitTag(t) {
+				carried[t] = true
+			}
+		}
+		for t := range carried {
+			counts[t]++
+		}
+	}
+
+	var unused []TagCount
+	for _, tc := range CountTags(append(slices.Clone(live), retired...)) {
+		if c := counts[tc.Tag]; c <= 1 {
+			unused = append(unused, TagCount{Tag: tc.Tag, Count: c})
+		}
+	}
+	sort.SliceStable(unused, func(i, j int) bool {
+		if unused[i].Count != unused[j].Count {
+			return unused[i].Count < unused[j].Count
+		}
+		return unused[i].Tag < unused[j].Tag
+	})
+	return unused
+}
+
+// TagPair is a pair of tags that look like variants of each other, such as a typo or a plural.
+type TagPair struct {
+	A      TagCount `json:"a"`
+	B      TagCount `json:"b"`
+	Reason string   `json:"reason"`
+}
+
+// SimilarTags returns the pairs of tags that look like variants of each other, with the more
+// used tag of each pair first. Tags are similar when they differ only in case or punctuation,
+// one is the plural of the other, one is the other with a "lang" suffix (go and golang),
+// or they are a single edit or swap apart (two for long tags).
+func SimilarTags(tags []TagCount) []TagPair {
+	var pairs []TagPair
+	for i := range tags {
+		for j := i + 1; j < len(tags); j++ {
+			a, b := tags[i], tags[j]
+			if b.Count > a.Count || (b.Count == a.Count && b.Tag < a.Tag) {
+				a, b = b, a
+			}
+			if reason := tagSimilarity(a.Tag, b.Tag); reason != "" {
+				pairs = append(pairs, TagPair{A: a, B: b, Reason: reason})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].A.Tag != pairs[j].A.Tag {
+			return pairs[i].A.Tag < pairs[j].A.Tag
+		}
+		return pairs[i].B.Tag < pairs[j].B.Tag
+	})
+	return pairs
+}
+
+// tagSimilarity returns why two tags look like variants of each other, or "" if they do not.
+// Tags under the same parent are compared by their last level, so that short siblings such as
+// proj/a and proj/b are not mistaken for typos.
+func tagSimilarity(a, b string) string {
+	if pa, la := splitTag(a); pa != "" {
+		if pb, lb := splitTag(b); pa == pb {
+			a, b = la, lb
+		}
+	}
+	na, nb := normalizeTag(a), normalizeTag(b)
+	short, long := na, nb
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	switch {
+	case na == nb:
+		return "differ only in case or punctuation"
+	case long == short+"s" || long == short+"es":
+		return "plural"
+	case long == short+"lang":
+		return "language name"
+	}
+	if limit := min(len(na), len(nb)); limit >= 4 {
+		maxEdits := 1
+		if limit >= 8 {
+			maxEdits = 2
+		}
+		if editDistance(na, nb) <= maxEdits {
+			return "possible typo"
+		}
+	}
+	return ""
+}
+
+// splitTag splits a tag into its parent, or "" for a top-level tag, and its last level.
+func splitTag(tag string) (string, string) {
+	if i := strings.LastIndexByte(tag, '/'); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return "", tag
+}
+
+// normalizeTag lowercases a tag and drops everything but letters and digits.
+func normalizeTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, tag)
+}
+
+// editDistance returns the number of single-rune insertions, deletions, substitutions and
+// swaps of adjacent runes needed to turn a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// ValidTag reports whether tag can be stored and given on the command line: it must be
+// non-empty, contain no commas or whitespace, and have no empty levels such as a leading,
+// trailing or doubled slash.
+func ValidTag(tag string) bool {
+	if tag == "" || strings.ContainsFunc(tag, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		return false
+	}
+	return !slices.Contains(strings.Split(tag, "/"), "")
+}
+
+// ValidateTags returns an error naming the first tag that is not a ValidTag, or nil if all are.
+func ValidateTags(tags []string) error {
+	for _, t := range tags {
+		if !ValidTag(t) {
+			return fmt.Errorf("invalid tag '%s': tags must not be empty, contain commas or spaces, or have empty levels", t)
+		}
+	}
+	return nil
+}
+
+// Retag records the tags of a note before and after they were rewritten.
+type Retag struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+}
+
+// RetagNotes replaces the tags in from with the tag to on every note that has one of them,
+// saving each changed note like SaveNote. Descendants move along with their tag, so renaming
+// proj/atlas to proj/apollo turns proj/atlas/infra into proj/apollo/infra. Notes that end up
+// with a tag twice keep it once, at its first position. Renaming a tag to one of its own
+// descendants leaves the tags already under the new name alone. With dryRun set, nothing is saved and
+// the changes that would be made are returned. Only notes in the notes area are rewritten;
+// archived and trashed notes are left as they are.
+func RetagNotes(cfg *Config, from []string, to string, dryRun bool) ([]Retag, error) {
+	if err := ValidateTags([]string{to}); err != nil {
+		return nil, err
+	}
+	from = slices.DeleteFunc(slices.Clone(from), func(t string) bool { return t == to })
+	if len(from) == 0 {
+		return nil, fmt.Errorf("nothing to retag: the tags are already '%s'", to)
+	}
+
+	entries, err := loadNoteEntries(cfg.Store())
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Retag
+	for _, e := range entries {
+		n := e.note
+		after, changed := replaceTags(n.Tags, from, to)
+		if !changed {
+			continue
+		}
+		change := Retag{ID: n.ID, Title: n.DisplayTitle(), Before: n.Tags, After: after}
+		if !dryRun {
+			n.Tags = after
+			n.UpdateTimestamp()
+			if err := saveNote(cfg, n, e.key); err != nil {
+				return changes, err
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// replaceTags returns tags with every tag in from, or descendant of one, moved to to and
+// duplicates dropped, and whether anything was replaced. When to is itself under a from tag,
+// as in renaming proj to proj/archive, tags already at or under to are left where they are
+// rather than being moved again to proj/archive/archive.
+func replaceTags(tags, from []string, to string) ([]string, bool) {
+	changed := false
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		for _, f := range from {
+			if TagMatches(to, f) && TagMatches(t, to) {
+				break
+			}
+			if TagMatches(t, f) {
+				t = to + t[len(strings.TrimSuffix(f, "/")):]
+				changed = true
+				break
+			}
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out, changed
+}
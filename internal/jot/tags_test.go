@@ -0,0 +1,93 @@
+package jot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReplaceTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		from    []string
+		to      string
+		want    []string
+		changed bool
+	}{
+		{"rename", []string{"go", "draft"}, []string{"draft"}, "wip", []string{"go", "wip"}, true},
+		{"children move with their parent", []string{"proj/atlas/infra", "proj"}, []string{"proj"}, "work", []string{"work/atlas/infra", "work"}, true},
+		{"a prefix of a level does not match", []string{"project"}, []string{"proj"}, "work", []string{"project"}, false},
+		{"trailing slash on the old tag", []string{"proj/a"}, []string{"proj/"}, "work", []string{"work/a"}, true},
+		{"move under itself", []string{"proj", "proj/archive/old"}, []string{"proj"}, "proj/archive", []string{"proj/archive", "proj/archive/old"}, true},
+		{"move under itself is idempotent", []string{"proj/archive"}, []string{"proj"}, "proj/archive", []string{"proj/archive"}, false},
+		{"merge drops duplicates", []string{"b/c", "b", "x"}, []string{"b/c"}, "b", []string{"b", "x"}, true},
+		{"merge several", []string{"golang", "go-lang", "go"}, []string{"golang", "go-lang"}, "go", []string{"go"}, true},
+		{"no match", []string{"go"}, []string{"rust"}, "zig", []string{"go"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := replaceTags(tt.tags, tt.from, tt.to)
+			if !slices.Equal(got, tt.want) || changed != tt.changed {
+				t.Errorf("replaceTags(%v, %v, %q) = %v, %v; want %v, %v", tt.tags, tt.from, tt.to, got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
+
+func TestUnusedTags(t *testing.T) {
+	live := []*Note{
+		{Tags: []string{"go", "proj/atlas/infra"}},
+		{Tags: []string{"go", "pyhton", "proj/atlas"}},
+		{Tags: []string{"go", "go"}},
+	}
+	retired := []*Note{{Tags: []string{"old", "go", "pyhton"}}}
+
+	var got []string
+	for _, tc := range UnusedTags(live, retired) {
+		got = append(got, fmt.Sprintf("%s=%d", tc.Tag, tc.Count))
+	}
+	// proj and proj/atlas are carried through proj/atlas/infra as well, so they are in use.
+	want := []string{"old=0", "proj/atlas/infra=1", "pyhton=1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("UnusedTags = %v, want %v", got, want)
+	}
+}
+
+func TestSimilarTags(t *testing.T) {
+	tags := []TagCount{
+		{"python", 9}, {"pyhton", 1},
+		{"golang", 2}, {"go", 5},
+		{"books", 3}, {"book", 1},
+		{"Draft", 1}, {"draft", 4},
+		{"proj/a", 2}, {"proj/b", 2},
+		{"work", 3}, {"home", 3},
+	}
+	var got []string
+	for _, p := range SimilarTags(tags) {
+		got = append(got, p.A.Tag+" ~ "+p.B.Tag+": "+p.Reason)
+	}
+	want := []string{
+		"books ~ book: plural",
+		"draft ~ Draft: differ only in case or punctuation",
+		"go ~ golang: language name",
+		"python ~ pyhton: possible typo",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("SimilarTags =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidTag(t *testing.T) {
+	for _, tag := range []string{"go", "proj/atlas/infra", "c++", "día"} {
+		if !ValidTag(tag) {
+			t.Errorf("ValidTag(%q) = false, want true", tag)
+		}
+	}
+	for _, tag := range []string{"", "a b", "a,b", "a\tb", "/a", "a/", "a//b"} {
+		if ValidTag(tag) {
+			t.Errorf("ValidTag(%q) = true, want false", tag)
+		}
+	}
+}
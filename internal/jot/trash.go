@@ -0,0 +1,116 @@
+package jot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrashNote moves the note with the given ID or ID prefix from the notes area into the trash.
+// Returns the keys the note was moved from and to.
+func TrashNote(s Store, id string) (string, string, error) {
+	return moveNote(s, notesArea, trashArea, id)
+}
+
+// RestoreNote moves the note with the given ID or ID prefix from the trash back into the notes area.
+// Returns the keys the note was moved from and to.
+func RestoreNote(s Store, id string) (string, string, error) {
+	return moveNote(s, trashArea, notesArea, id)
+}
+
+// ArchiveNote moves the note with the given ID or ID prefix from the notes area into the archive.
+// Archived notes are not returned by LoadAllNotes.
+// Returns the keys the note was moved from and to.
+func ArchiveNote(s Store, id string) (string, string, error) {
+	return moveNote(s, notesArea, archiveArea, id)
+}
+
+// UnarchiveNote moves the note with the given ID or ID prefix from the archive back into the notes area.
+// Returns the keys the note was moved from and to.
+func UnarchiveNote(s Store, id string) (string, string, error) {
+	return moveNote(s, archiveArea, notesArea, id)
+}
+
+// LoadTrashedNotes loads all notes currently in the trash.
+func LoadTrashedNotes(s Store) ([]*Note, error) {
+	return loadNotesOf(s, trashArea)
+}
+
+// LoadArchivedNotes loads all archived notes.
+func LoadArchivedNotes(s Store) ([]*Note, error) {
+	return loadNotesOf(s, archiveArea)
+}
+
+// loadNotesOf loads every note in an area of the store.
+func loadNotesOf(s Store, area string) ([]*Note, error) {
+	entries, err := loadNotesIn(s, area)
+	return entryNotes(entries), err
+}
+
+// EmptyTrash permanently deletes every note in the trash.
+// Returns the number of notes removed.
+func EmptyTrash(s Store) (int, error) {
+	keys, err := s.List(trashArea)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	removed := 0
+	for _, key := range keys {
+		if err := s.Delete(key); err != nil {
+			return removed, fmt.Errorf("failed to remove trashed note '%s': %w", key, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// moveNote moves a note between two areas of the store, keeping its path within the area,
+// so a note filed in a directory by the layout returns to it when restored.
+// It refuses to overwrite a note that already exists in the destination, except in the
+// trash, where the moved note gets a timestamp suffix instead. It also refuses to move a note
+// back into the notes area while another note there has its ID, such as when a note was
+// trashed, a new one created with its ID and the first then restored.
+func moveNote(s Store, from, to, id string) (string, string, error) {
+	src, err := resolveKey(s, from, id)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := s.Get(src)
+	if err != nil {
+		return "", "", err
+	}
+	if to == notesArea {
+		n, err := ParseNote(data, src)
+		if err != nil {
+			return "", "", err
+		}
+		used, err := idInArea(s, notesArea, n.ID)
+		if err != nil {
+			return "", "", err
+		}
+		if used && n.ID != "" {
+			return "", "", fmt.Errorf("cannot move note '%s' into the notes: %w", n.ID, ErrNoteExists)
+		}
+	}
+
+	rel := strings.TrimPrefix(src, from)
+	dest := to + rel
+	if _, err := s.Get(dest); err == nil {
+		if to != trashArea {
+			return "", "", fmt.Errorf("cannot move note '%s': a note already exists at '%s'", id, dest)
+		}
+		dest = fmt.Sprintf("%s%s.%s", to, rel, time.Now().Format("20060102150405"))
+	} else if !errors.Is(err, ErrNotFound) {
+		return "", "", err
+	}
+
+	if err := s.Put(dest, data); err != nil {
+		return "", "", fmt.Errorf("failed to move note from '%s' to '%s': %w", src, dest, err)
+	}
+	if err := s.Delete(src); err != nil {
+		return "", "", fmt.Errorf("failed to move note from '%s' to '%s': %w", src, dest, err)
+	}
+	return src, dest, nil
+}
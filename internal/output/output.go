@@ -0,0 +1,339 @@
+// Package output writes listings of notes in the formats chosen with --format and --columns:
+// a command's own text layout, JSON, newline-delimited JSON, CSV, TSV, YAML or a Go template.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/dalryan/jot/internal/jot"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats. Any other format containing "{{" is a Go text/template.
+const (
+	Text   = "text"
+	JSON   = "json"
+	NDJSON = "ndjson"
+	CSV    = "csv"
+	TSV    = "tsv"
+	YAML   = "yaml"
+)
+
+// DefaultColumns are the columns of CSV and TSV output when none are chosen.
+var DefaultColumns = []string{"id", "created", "context", "tags", "title"}
+
+// Columns lists the note columns that can be chosen, in the order they are suggested.
+// Any other column name selects the extra frontmatter field of that name.
+var Columns = []string{"id", "short_id", "title", "created", "updated", "context", "tags", "links", "content"}
+
+// Printer writes notes in one output format.
+type Printer struct {
+	// Text writes row i in the command's own layout. It is used by the text format
+	// when no columns are chosen; without it, the default columns are printed.
+	Text func(w io.Writer, i int) error
+	// Document returns what the json, ndjson and yaml formats encode for row i when no
+	// columns are chosen. Without it, the note itself is encoded.
+	Document func(i int) any
+	// Short abbreviates IDs for the short_id column and the short template function.
+	Short jot.ShortIDs
+
+	format  string
+	columns []string
+	tmpl    *template.Template
+}
+
+// New returns a printer for the format and columns, which may be empty to use the defaults.
+func New(format string, columns []string) (*Printer, error) {
+	p := &Printer{format: format, columns: columns}
+	switch format {
+	case "":
+		p.format = Text
+	case Text, JSON, NDJSON, CSV, TSV, YAML:
+	default:
+		if !strings.Contains(format, "{{") {
+			return nil, fmt.Errorf("unknown output format '%s': use text, json, ndjson, csv, tsv, yaml or a Go template such as '{{.ID}} {{.DisplayTitle}}'", format)
+		}
+		funcs := template.FuncMap{
+			"short": func(id string) string { return p.Short.Of(id) },
+			"join":  strings.Join,
+			"date":  func(layout string, t time.Time) string { return t.Format(layout) },
+			"field": func(n *jot.Note, key string) string { v, _ := n.Field(key); return v },
+		}
+		tmpl, err := template.New("format").Funcs(funcs).Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output template: %w", err)
+		}
+		p.tmpl = tmpl
+	}
+	return p, nil
+}
+
+// Format returns the output format, or "template" for a Go template.
+func (p *Printer) Format() string {
+	if p.tmpl != nil {
+		return "template"
+	}
+	return p.format
+}
+
+// Print writes the notes to w.
+func (p *Printer) Print(w io.Writer, notes []*jot.Note) error {
+	if p.tmpl != nil {
+		return p.printTemplate(w, notes)
+	}
+	switch p.format {
+	case JSON, NDJSON, YAML:
+		return p.printDocuments(w, notes)
+	case CSV, TSV:
+		return p.printTable(w, notes)
+	}
+	if p.Text != nil && len(p.columns) == 0 {
+		for i := range notes {
+			if err := p.Text(w, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return p.printColumns(w, notes)
+}
+
+// printTemplate executes the template for each note, ending each with a newline unless the
+// template already does.
+func (p *Printer) printTemplate(w io.Writer, notes []*jot.Note) error {
+	for _, n := range notes {
+		var buf bytes.Buffer
+		if err := p.tmpl.Execute(&buf, n); err != nil {
+			return fmt.Errorf("failed to execute output template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printDocuments writes one document per note as a JSON array, JSON lines or a YAML sequence.
+func (p *Printer) printDocuments(w io.Writer, notes []*jot.Note) error {
+	docs := make([]any, len(notes))
+	for i, n := range notes {
+		switch {
+		case len(p.columns) > 0:
+			docs[i] = p.row(n)
+		case p.Document != nil:
+			docs[i] = p.Document(i)
+		default:
+			docs[i] = n
+		}
+	}
+
+	switch p.format {
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, doc := range docs {
+			if err := enc.Encode(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	case YAML:
+		return encodeYAML(w, docs)
+	}
+	return json.NewEncoder(w).Encode(docs)
+}
+
+// printTable writes a header and one record per note, separated by commas or tabs.
+func (p *Printer) printTable(w io.Writer, notes []*jot.Note) error {
+	columns := p.columnNames()
+	records := [][]string{columns}
+	for _, n := range notes {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = formatValue(p.value(n, c), time.RFC3339)
+		}
+		records = append(records, record)
+	}
+
+	if p.format == TSV {
+		// TSV has no quoting, so separators inside values become spaces.
+		clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ")
+		for _, record := range records {
+			for i, v := range record {
+				record[i] = clean.Replace(v)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(record, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// printColumns writes the chosen columns of each note aligned in a table without a header,
+// so that the first word of every line is still the first column.
+func (p *Printer) printColumns(w io.Writer, notes []*jot.Note) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	columns := p.columnNames()
+	for _, n := range notes {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			values[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(formatValue(p.value(n, c), "2006-01-02 15:04"))
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// columnNames returns the chosen columns, or the defaults.
+func (p *Printer) columnNames() []string {
+	if len(p.columns) > 0 {
+		return p.columns
+	}
+	return DefaultColumns
+}
+
+// row returns the chosen columns of a note as a document that keeps the column order.
+func (p *Printer) row(n *jot.Note) orderedRow {
+	row := make(orderedRow, len(p.columns))
+	for i, c := range p.columns {
+		row[i] = column{name: c, value: p.value(n, c)}
+	}
+	return row
+}
+
+// value returns a column of a note. Lists and times keep their type for JSON and YAML;
+// a missing extra field is nil.
+func (p *Printer) value(n *jot.Note, name string) any {
+	switch name {
+	case "id":
+		return n.ID
+	case "short_id":
+		return p.Short.Of(n.ID)
+	case "title":
+		return n.DisplayTitle()
+	case "created", "created_at":
+		return n.CreatedAt
+	case "updated", "updated_at":
+		return n.UpdatedAt
+	case "context":
+		return n.Context
+	case "tags":
+		return nonNil(n.Tags)
+	case "links":
+		return nonNil(n.Links)
+	case "content":
+		return n.Content
+	}
+	v, _ := n.Extra.Get(name)
+	return v
+}
+
+// formatValue renders a column value as text, with times in the given layout and lists joined by commas.
+func formatValue(v any, layout string) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(layout)
+	case []string:
+		return strings.Join(v, ",")
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item, layout)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// nonNil returns an empty list instead of nil, so that it encodes as [] rather than null.
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+// column is a named value in an orderedRow.
+type column struct {
+	name  string
+	value any
+}
+
+// orderedRow is a document of chosen columns that encodes as a JSON object in column order.
+type orderedRow []column
+
+// MarshalJSON encodes the row as a JSON object, keeping the column order.
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, c := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(c.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(c.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encodeYAML writes documents as a YAML sequence. They are encoded through JSON so that YAML
+// output has the same fields, in the same order, as JSON output.
+func encodeYAML(w io.Writer, docs []any) error {
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	plainStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// plainStyle clears the JSON flow and quoting styles from a node tree, so that it is written
+// as block YAML. Strings that would read back as another type stay quoted.
+func plainStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		plainStyle(c)
+	}
+}
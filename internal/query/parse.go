@@ -0,0 +1,374 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+// ParseError reports a query that could not be parsed, pointing at the offending token.
+type ParseError struct {
+	// Query is the query that was parsed.
+	Query string
+	// Pos is the byte offset of the offending token in Query.
+	Pos int
+	// Msg describes the problem.
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid query: %s at column %d", e.Msg, utf8.RuneCountInString(e.Query[:e.Pos])+1)
+}
+
+// Pointer renders the query with a caret under the offending token, for showing below the error.
+func (e *ParseError) Pointer() string {
+	return e.Query + "\n" + strings.Repeat(" ", utf8.RuneCountInString(e.Query[:e.Pos])) + "^"
+}
+
+// fields lists the field names a term can start with, in the order they are suggested.
+var fields = []string{"tag", "context", "title", "id", "field", "created", "updated"}
+
+// Parse parses a query. Relative times such as updated:<7d are taken relative to now.
+// An empty query matches every note.
+func Parse(q string) (Expr, error) {
+	return ParseAt(q, time.Now())
+}
+
+// ParseAt parses a query with relative times taken relative to the given time.
+func ParseAt(q string, now time.Time) (Expr, error) {
+	toks, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{query: q, toks: toks, now: now}
+	if p.peek().kind == tokEOF {
+		return And{}, nil
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected '%s'", t.text))
+	}
+	return e, nil
+}
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokLParen
+	tokRParen
+	tokMinus
+	tokOr
+	tokAnd
+	tokNot
+)
+
+// token is a lexical token of a query.
+type token struct {
+	kind tokenKind
+	// text is the token as written, used in error messages.
+	text string
+	// value is the token with quotes removed.
+	value string
+	pos   int
+}
+
+// lex splits a query into tokens.
+func lex(q string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(q) {
+		r, size := utf8.DecodeRuneInString(q[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			toks = append(toks, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == '-' && i+1 < len(q) && !unicode.IsSpace(rune(q[i+1])) && q[i+1] != ')':
+			toks = append(toks, token{kind: tokMinus, text: "-", pos: i})
+			i++
+		case r == '"':
+			value, end, err := lexQuoted(q, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tokPhrase, text: q[i:end], value: value, pos: i})
+			i = end
+		default:
+			start := i
+			var value strings.Builder
+			quoted := false
+			for i < len(q) {
+				r, size := utf8.DecodeRuneInString(q[i:])
+				if unicode.IsSpace(r) || r == '(' || r == ')' {
+					break
+				}
+				if r == '"' {
+					s, end, err := lexQuoted(q, i)
+					if err != nil {
+						return nil, err
+					}
+					value.WriteString(s)
+					quoted = true
+					i = end
+					continue
+				}
+				value.WriteRune(r)
+				i += size
+			}
+			t := token{kind: tokWord, text: q[start:i], value: value.String(), pos: start}
+			if !quoted {
+				switch t.text {
+				case "OR":
+					t.kind = tokOr
+				case "AND":
+					t.kind = tokAnd
+				case "NOT":
+					t.kind = tokNot
+				}
+			}
+			toks = append(toks, t)
+		}
+	}
+	return append(toks, token{kind: tokEOF, text: "end of query", pos: len(q)}), nil
+}
+
+// lexQuoted reads the double-quoted string starting at q[start], where \" and \\ are escapes.
+// Returns its contents and the offset just past the closing quote.
+func lexQuoted(q string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(q); i++ {
+		switch q[i] {
+		case '\\':
+			if i+1 < len(q) && (q[i+1] == '"' || q[i+1] == '\\') {
+				i++
+			}
+			b.WriteByte(q[i])
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(q[i])
+		}
+	}
+	return "", 0, &ParseError{Query: q, Pos: start, Msg: "unterminated quote"}
+}
+
+// parser is a recursive descent parser over the tokens of a query.
+type parser struct {
+	query string
+	toks  []token
+	i     int
+	now   time.Time
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, msg string) *ParseError {
+	return &ParseError{Query: p.query, Pos: t.pos, Msg: msg}
+}
+
+// parseOr parses terms joined by OR, which binds looser than AND.
+func (p *parser) parseOr() (Expr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := Or{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, e)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+// parseAnd parses a run of terms that must all match, optionally joined by AND.
+func (p *parser) parseAnd() (Expr, error) {
+	var terms And
+	for {
+		switch p.peek().kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(terms) == 0 {
+				t := p.peek()
+				return nil, p.errorAt(t, fmt.Sprintf("expected a search term but found '%s'", t.text))
+			}
+			if len(terms) == 1 {
+				return terms[0], nil
+			}
+			return terms, nil
+		case tokAnd:
+			if len(terms) == 0 {
+				return nil, p.errorAt(p.peek(), "unexpected 'AND'")
+			}
+			p.next()
+			if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+				t := p.peek()
+				return nil, p.errorAt(t, fmt.Sprintf("expected a search term after 'AND' but found '%s'", t.text))
+			}
+		}
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, e)
+	}
+}
+
+// parseUnary parses a term, negated with a leading - or NOT.
+func (p *parser) parseUnary() (Expr, error) {
+	if k := p.peek().kind; k == tokMinus || k == tokNot {
+		op := p.next()
+		if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+			return nil, p.errorAt(p.peek(), fmt.Sprintf("expected a search term after '%s'", op.text))
+		}
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: e}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a parenthesised group, a quoted phrase or a single term.
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorAt(t, "missing ')' to close this '('")
+		}
+		p.next()
+		return e, nil
+	case tokPhrase:
+		return Text{Text: t.value}, nil
+	case tokWord:
+		return p.parseTerm(t)
+	}
+	return nil, p.errorAt(t, fmt.Sprintf("unexpected '%s'", t.text))
+}
+
+// parseTerm parses a word: a field:value term, or a bare word matched against title and content.
+func (p *parser) parseTerm(t token) (Expr, error) {
+	name, value, ok := strings.Cut(t.value, ":")
+	if !ok || name == "" || !isFieldName(name) || strings.HasPrefix(value, "//") {
+		return Text{Text: t.value}, nil
+	}
+
+	valuePos := t.pos + strings.Index(t.text, ":") + 1
+	if value == "" {
+		return nil, &ParseError{Query: p.query, Pos: valuePos, Msg: fmt.Sprintf("missing value after '%s:'", name)}
+	}
+
+	switch strings.ToLower(name) {
+	case "tag":
+		return Tag{Tag: value}, nil
+	case "context":
+		return Context{Context: value}, nil
+	case "title":
+		return Title{Text: value}, nil
+	case "id":
+		return ID{Prefix: value}, nil
+	case "field":
+		key, v, _ := strings.Cut(value, "=")
+		return Field{Key: strings.TrimSpace(key), Value: strings.TrimSpace(v)}, nil
+	case "created", "updated":
+		e, err := p.parseTime(value)
+		if err != nil {
+			return nil, &ParseError{Query: p.query, Pos: valuePos, Msg: err.Error()}
+		}
+		e.Updated = strings.EqualFold(name, "updated")
+		return e, nil
+	}
+	return nil, p.errorAt(t, fmt.Sprintf("unknown field '%s' (use %s)", name, strings.Join(fields, ", ")))
+}
+
+// isFieldName reports whether name looks like a field name rather than part of a word
+// such as a URL, so that a misspelt field is reported instead of searched for.
+func isFieldName(name string) bool {
+	for _, r := range name {
+		if !unicode.IsLetter(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTime parses the value of a created: or updated: term: an optional comparison
+// followed by a date (2026-01-02), a date and time (2026-01-02T15:04 or RFC 3339) or a
+// relative time (7d, 2w, 1y or a Go duration such as 36h) counted back from now.
+func (p *parser) parseTime(value string) (Time, error) {
+	op := ""
+	for _, o := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, o) {
+			op, value = o, value[len(o):]
+			break
+		}
+	}
+	if value == "" {
+		return Time{}, fmt.Errorf("missing date after '%s'", op)
+	}
+
+	if at, ok := jot.ParseAgo(value, p.now); ok {
+		// An age below a duration is a time after the point that long ago, and vice versa.
+		flip := map[string]string{"": ">=", "<": ">", "<=": ">=", ">": "<", ">=": "<=", "=": "="}
+		return Time{Op: flip[op], At: at}, nil
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", value, p.now.Location()); err == nil {
+		// A whole day: created:>2026-01-01 means after that day, not after its first instant.
+		switch op {
+		case "", "=":
+			return Time{Op: "=", At: day}, nil
+		case ">":
+			return Time{Op: ">=", At: day.AddDate(0, 0, 1)}, nil
+		case "<=":
+			return Time{Op: "<", At: day.AddDate(0, 0, 1)}, nil
+		}
+		return Time{Op: op, At: day}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, p.now.Location()); err == nil {
+			if op == "" {
+				op = "="
+			}
+			return Time{Op: op, At: t}, nil
+		}
+	}
+	return Time{}, fmt.Errorf("invalid date '%s' (use a date like 2026-01-02 or a relative time like 7d)", value)
+}
@@ -0,0 +1,126 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+var now = time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`go`, `go`},
+		{`tag:go -tag:draft`, `(tag:go -tag:draft)`},
+		{`tag:go AND context:work`, `(tag:go context:work)`},
+		{`a b OR c`, `((a b) OR c)`},
+		{`(context:work OR context:oss) NOT tag:draft`, `((context:work OR context:oss) -tag:draft)`},
+		{`"exact phrase" title:"weekly review"`, `("exact phrase" title:"weekly review")`},
+		{`field:status=open field:due`, `(field:status=open field:due)`},
+		{`https://example.com`, `https://example.com`},
+		{`created:2026-01-02`, `created:=2026-01-02T00:00:00Z`},
+		{`created:>2026-01-02`, `created:>=2026-01-03T00:00:00Z`},
+		{`created:<=2026-01-02`, `created:<2026-01-03T00:00:00Z`},
+		{`updated:<7d`, `updated:>2026-03-08T12:00:00Z`},
+		{`updated:>2w`, `updated:<2026-03-01T12:00:00Z`},
+		{`created:36h`, `created:>=2026-03-14T00:00:00Z`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			e, err := ParseAt(tt.query, now)
+			if err != nil {
+				t.Fatalf("ParseAt(%q): %v", tt.query, err)
+			}
+			if got := e.String(); got != tt.want {
+				t.Errorf("ParseAt(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		msg     string
+		pointer string
+	}{
+		{`tag:go (context:work`, "missing ')' to close this '('", "tag:go (context:work\n       ^"},
+		{`tag:go )`, "unexpected ')'", "tag:go )\n       ^"},
+		{`tag:`, "missing value after 'tag:'", "tag:\n    ^"},
+		{`a OR`, "expected a search term but found 'end of query'", "a OR\n    ^"},
+		{`AND a`, "unexpected 'AND'", "AND a\n^"},
+		{`a AND OR b`, "expected a search term after 'AND' but found 'OR'", "a AND OR b\n      ^"},
+		{`NOT`, "expected a search term after 'NOT'", "NOT\n   ^"},
+		{`title:"open`, "unterminated quote", "title:\"open\n      ^"},
+		{`colour:red`, "unknown field 'colour' (use tag, context, title, id, field, created, updated)", "colour:red\n^"},
+		{`créé:x titel:y`, "unknown field 'créé' (use tag, context, title, id, field, created, updated)", "créé:x titel:y\n^"},
+		{`go updated:<soon`, "invalid date 'soon' (use a date like 2026-01-02 or a relative time like 7d)", "go updated:<soon\n           ^"},
+		{`é created:-1h`, "invalid date '-1h' (use a date like 2026-01-02 or a relative time like 7d)", "é created:-1h\n          ^"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseAt(tt.query, now)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("ParseAt(%q) error = %v, want a *ParseError", tt.query, err)
+			}
+			if perr.Msg != tt.msg {
+				t.Errorf("message = %q, want %q", perr.Msg, tt.msg)
+			}
+			if got := perr.Pointer(); got != tt.pointer {
+				t.Errorf("pointer =\n%s\nwant\n%s", got, tt.pointer)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	note := &jot.Note{
+		ID:        "6be4ed32",
+		Title:     "Weekly review",
+		CreatedAt: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC),
+		Context:   "work",
+		Tags:      []string{"proj/atlas/infra", "go"},
+		Content:   "Ship the ingress controller.",
+	}
+	if err := note.Extra.Set("status", "open"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`ingress`, true},
+		{`INGRESS controller`, true},
+		{`"the ingress"`, true},
+		{`tag:proj/atlas`, true},
+		{`tag:proj/at`, false},
+		{`-tag:go`, false},
+		{`context:home OR tag:go`, true},
+		{`id:6be4`, true},
+		{`title:weekly`, true},
+		{`field:status=open`, true},
+		{`field:status=closed`, false},
+		{`field:context=work`, true},
+		{`created:2026-03-10`, true},
+		{`created:>2026-03-10`, false},
+		{`updated:<7d`, true},
+		{`created:<2d`, false},
+	}
+	for _, tt := range tests {
+		e, err := ParseAt(tt.query, now)
+		if err != nil {
+			t.Fatalf("ParseAt(%q): %v", tt.query, err)
+		}
+		if got := e.Match(note); got != tt.want {
+			t.Errorf("%q matched = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
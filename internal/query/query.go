@@ -0,0 +1,192 @@
+// Package query parses and evaluates the note query language used by --query, such as
+//
+//	tag:go -tag:draft (context:work OR context:oss) created:>2026-01-01 updated:<7d "exact phrase" field:status=open
+//
+// Terms next to each other must all match; OR joins alternatives, a leading - or NOT negates
+// a term and parentheses group. Bare words and quoted phrases match the note's title and content.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+// Expr is a parsed query that can be evaluated against notes.
+type Expr interface {
+	// Match reports whether the note satisfies the expression.
+	Match(n *jot.Note) bool
+	// String renders the expression in canonical form, mostly for debugging.
+	String() string
+}
+
+// And matches notes that match every one of its terms.
+type And []Expr
+
+// Or matches notes that match at least one of its terms.
+type Or []Expr
+
+// Not matches notes that do not match its term.
+type Not struct {
+	Expr Expr
+}
+
+// Text matches notes whose title or content contains the text, ignoring case.
+type Text struct {
+	Text string
+}
+
+// Tag matches notes with the given tag.
+type Tag struct {
+	Tag string
+}
+
+// Context matches notes in the given context.
+type Context struct {
+	Context string
+}
+
+// Title matches notes whose title contains the text, ignoring case.
+type Title struct {
+	Text string
+}
+
+// ID matches notes whose ID starts with the prefix.
+type ID struct {
+	Prefix string
+}
+
+// Field matches notes with a frontmatter field, and with the given value if one is set.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Time matches notes whose creation or update time compares to a point in time.
+type Time struct {
+	// Updated selects UpdatedAt instead of CreatedAt.
+	Updated bool
+	// Op is one of "<", "<=", ">", ">=" or "=", where "=" means within the same day as At.
+	Op string
+	// At is the point in time compared against.
+	At time.Time
+}
+
+// Match reports whether n matches every term.
+func (e And) Match(n *jot.Note) bool {
+	for _, t := range e {
+		if !t.Match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether n matches any term.
+func (e Or) Match(n *jot.Note) bool {
+	for _, t := range e {
+		if t.Match(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether n does not match the negated term.
+func (e Not) Match(n *jot.Note) bool {
+	return !e.Expr.Match(n)
+}
+
+// Match reports whether the title or content of n contains the text.
+func (e Text) Match(n *jot.Note) bool {
+	text := strings.ToLower(e.Text)
+	return strings.Contains(strings.ToLower(n.Title), text) || strings.Contains(strings.ToLower(n.Content), text)
+}
+
+// Match reports whether n has the tag.
+func (e Tag) Match(n *jot.Note) bool {
+	return jot.HasAllTags(n, []string{e.Tag})
+}
+
+// Match reports whether n is in the context.
+func (e Context) Match(n *jot.Note) bool {
+	return n.Context == e.Context
+}
+
+// Match reports whether the title of n contains the text.
+func (e Title) Match(n *jot.Note) bool {
+	return strings.Contains(strings.ToLower(n.DisplayTitle()), strings.ToLower(e.Text))
+}
+
+// Match reports whether the ID of n starts with the prefix.
+func (e ID) Match(n *jot.Note) bool {
+	return strings.HasPrefix(n.ID, e.Prefix)
+}
+
+// Match reports whether n has the field, with the value if one is set.
+func (e Field) Match(n *jot.Note) bool {
+	return n.MatchesField(e.Key, e.Value)
+}
+
+// Match reports whether the creation or update time of n compares to the point in time.
+func (e Time) Match(n *jot.Note) bool {
+	t := n.CreatedAt
+	if e.Updated {
+		t = n.UpdatedAt
+	}
+	switch e.Op {
+	case "<":
+		return t.Before(e.At)
+	case "<=":
+		return !t.After(e.At)
+	case ">":
+		return t.After(e.At)
+	case ">=":
+		return !t.Before(e.At)
+	}
+	y1, m1, d1 := t.In(e.At.Location()).Date()
+	y2, m2, d2 := e.At.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+func (e And) String() string     { return "(" + join(e, " ") + ")" }
+func (e Or) String() string      { return "(" + join(e, " OR ") + ")" }
+func (e Not) String() string     { return "-" + e.Expr.String() }
+func (e Text) String() string    { return quote(e.Text) }
+func (e Tag) String() string     { return "tag:" + quote(e.Tag) }
+func (e Context) String() string { return "context:" + quote(e.Context) }
+func (e Title) String() string   { return "title:" + quote(e.Text) }
+func (e ID) String() string      { return "id:" + quote(e.Prefix) }
+
+func (e Field) String() string {
+	if e.Value == "" {
+		return "field:" + quote(e.Key)
+	}
+	return "field:" + quote(e.Key+"="+e.Value)
+}
+func (e Time) String() string {
+	name := "created"
+	if e.Updated {
+		name = "updated"
+	}
+	return fmt.Sprintf("%s:%s%s", name, e.Op, e.At.Format(time.RFC3339))
+}
+
+// join renders a list of expressions separated by sep.
+func join(exprs []Expr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// quote renders a value, quoting it if it would not read back as a single word.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t()\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,253 @@
+// Package search provides ranked full-text search over note content.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dalryan/jot/internal/jot"
+)
+
+// BM25 tuning parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// titleWeight is how many times a word in a note's title field counts compared to one in its content.
+const titleWeight = 3
+
+// Result is a single note matched by a search query.
+type Result struct {
+	// Note is the matching note.
+	Note *jot.Note `json:"note"`
+	// Score is the BM25 relevance score; higher is more relevant.
+	Score float64 `json:"score"`
+	// Snippets are short excerpts of the note content around each hit.
+	Snippets []string `json:"snippets"`
+}
+
+// Index is an in-memory inverted index over a set of notes.
+type Index struct {
+	docs   []document
+	df     map[string]int
+	avgLen float64
+}
+
+// document is a note together with its term frequencies.
+type document struct {
+	note   *jot.Note
+	tf     map[string]int
+	length int
+}
+
+// Token is a single normalised word found in a text, with its byte offsets.
+type Token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// NewIndex builds an index over the given notes.
+func NewIndex(notes []*jot.Note) *Index {
+	ix := &Index{df: make(map[string]int)}
+	total := 0
+
+	for _, n := range notes {
+		doc := document{note: n, tf: make(map[string]int)}
+		for _, t := range Tokenize(n.Title) {
+			doc.tf[t.Text] += titleWeight
+			doc.length += titleWeight
+		}
+		for _, t := range Tokenize(n.Content) {
+			doc.tf[t.Text]++
+			doc.length++
+		}
+		for term := range doc.tf {
+			ix.df[term]++
+		}
+		total += doc.length
+		ix.docs = append(ix.docs, doc)
+	}
+
+	if len(ix.docs) > 0 {
+		ix.avgLen = float64(total) / float64(len(ix.docs))
+	}
+	return ix
+}
+
+// Search ranks the indexed notes against the query using BM25.
+// Only notes containing at least one query term are returned, best match first.
+// Each result carries up to maxSnippets excerpts around the hits, with matched
+// words wrapped by the highlight function (which may be nil).
+func (ix *Index) Search(query string, maxSnippets int, highlight func(string) string) []Result {
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []Result
+	n := float64(len(ix.docs))
+	for _, doc := range ix.docs {
+		var score float64
+		for _, term := range terms {
+			tf := float64(doc.tf[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(ix.df[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - b + b*float64(doc.length)/ix.avgLen
+			score += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+		if score == 0 {
+			continue
+		}
+		results = append(results, Result{
+			Note:     doc.note,
+			Score:    score,
+			Snippets: Snippets(doc.note.Content, terms, maxSnippets, highlight),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Note.UpdatedAt.After(results[j].Note.UpdatedAt)
+	})
+	return results
+}
+
+// Tokenize splits text into lower-cased words made of letters and digits.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Text: strings.ToLower(text[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Text: strings.ToLower(text[start:]), Start: start, End: len(text)})
+	}
+	return tokens
+}
+
+// QueryTerms tokenizes a query into its distinct terms, in order of first appearance.
+func QueryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, t := range Tokenize(query) {
+		if !seen[t.Text] {
+			seen[t.Text] = true
+			terms = append(terms, t.Text)
+		}
+	}
+	return terms
+}
+
+// snippetRadius is the number of bytes of context shown on each side of a hit.
+const snippetRadius = 40
+
+// Snippets extracts up to limit single-line excerpts of text around occurrences of terms.
+// Matched words are passed through highlight if it is non-nil.
+func Snippets(text string, terms []string, limit int, highlight func(string) string) []string {
+	want := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		want[t] = true
+	}
+
+	var hits []Token
+	for _, t := range Tokenize(text) {
+		if want[t.Text] {
+			hits = append(hits, t)
+		}
+	}
+
+	var snippets []string
+	end := -1
+	for i := 0; i < len(hits) && len(snippets) < limit; i++ {
+		if hits[i].Start < end {
+			continue
+		}
+		from := max(clampRune(text, hits[i].Start-snippetRadius, false), end)
+		to := clampRune(text, hits[i].End+snippetRadius, true)
+		from, to = trimPartialWords(text, from, to, hits[i])
+
+		var sb strings.Builder
+		if from > 0 {
+			sb.WriteString("…")
+		}
+		pos := from
+		for _, h := range hits[i:] {
+			if h.End > to {
+				break
+			}
+			sb.WriteString(text[pos:h.Start])
+			if highlight != nil {
+				sb.WriteString(highlight(text[h.Start:h.End]))
+			} else {
+				sb.WriteString(text[h.Start:h.End])
+			}
+			pos = h.End
+		}
+		sb.WriteString(text[pos:to])
+		if to < len(text) {
+			sb.WriteString("…")
+		}
+
+		snippets = append(snippets, strings.Join(strings.Fields(sb.String()), " "))
+		end = to
+	}
+	return snippets
+}
+
+// clampRune clamps i to the bounds of text and moves it onto a rune boundary,
+// forwards if forward is true and backwards otherwise.
+func clampRune(text string, i int, forward bool) int {
+	if i <= 0 {
+		return 0
+	}
+	if i >= len(text) {
+		return len(text)
+	}
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		if forward {
+			i++
+		} else {
+			i--
+		}
+	}
+	return i
+}
+
+// trimPartialWords shrinks the window [from, to) so that it does not start or end
+// in the middle of a word, without cutting into the hit itself.
+func trimPartialWords(text string, from, to int, hit Token) (int, int) {
+	if from > 0 && !unicode.IsSpace(rune(text[from-1])) {
+		if idx := strings.IndexFunc(text[from:hit.Start], unicode.IsSpace); idx >= 0 {
+			from += idx + 1
+		} else {
+			from = hit.Start
+		}
+	}
+	if to < len(text) && !unicode.IsSpace(rune(text[to])) {
+		if idx := strings.LastIndexFunc(text[hit.End:to], unicode.IsSpace); idx >= 0 {
+			to = hit.End + idx
+		} else {
+			to = hit.End
+		}
+	}
+	return from, to
+}